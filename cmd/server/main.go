@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/ayeshakhan-29/test-task-BE/internal/app/handlers"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
 	"github.com/ayeshakhan-29/test-task-BE/internal/config"
 	"github.com/ayeshakhan-29/test-task-BE/internal/database"
 	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
@@ -55,8 +56,19 @@ func main() {
 		logger.Fatalf("Error running database migrations: %v", err)
 	}
 
+	svc := services.New(db.DB, cfg)
+
+	// Merge tracked URLs that are the same page once canonicalized
+	if err := services.CanonicalizeTrackedURLs(db.DB, svc.URLs); err != nil {
+		logger.Fatalf("Error canonicalizing tracked URLs: %v", err)
+	}
+
+	// Start background services (crawl workers and scheduler)
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	svc.Start(bgCtx)
+
 	// Initialize router with middleware
-	router := setupRouter(db)
+	router := setupRouter(db, cfg, svc)
 
 	// Create HTTP server with timeouts
 	srv := &http.Server{
@@ -93,16 +105,20 @@ func main() {
 		logger.Fatal("Server forced to shutdown: %v", err)
 	}
 
+	// Stop background services
+	stopBackground()
+	svc.Wait(5 * time.Second)
+
 	logger.Info("Server exited properly")
 }
 
 // setupRouter initializes and configures the Gin router with middleware and routes
-func setupRouter(db *database.Database) *gin.Engine {
+func setupRouter(db *database.Database, cfg *config.Config, svc *services.Services) *gin.Engine {
 	// Create a new Gin router with default middleware
 	router := gin.New()
 
 	// Add middleware
-	handlers.SetupRoutes(router, db)
+	handlers.SetupRoutes(router, db, cfg, svc)
 
 	return router
 }
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/ayeshakhan-29/test-task-BE/internal/database"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AlertHandler struct {
+	db *database.Database
+}
+
+func NewAlertHandler(db *database.Database) *AlertHandler {
+	return &AlertHandler{db: db}
+}
+
+// CreateAlertRule adds a rule that is evaluated after every crawl
+func (h *AlertHandler) CreateAlertRule(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	rule := models.AlertRule{
+		UserID:       userID.(uint64),
+		Type:         req.Type,
+		TrackedURLID: req.TrackedURLID,
+		Enabled:      true,
+	}
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := prepareAlertRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if rule.TrackedURLID != nil {
+		var tracked models.TrackedURL
+		if err := h.db.DB.Where("id = ? AND user_id = ?", *rule.TrackedURLID, rule.UserID).
+			First(&tracked).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tracked URL not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+			return
+		}
+	}
+
+	if err := h.db.DB.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListAlertRules lists the user's alert rules
+func (h *AlertHandler) ListAlertRules(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	rules := make([]models.AlertRule, 0)
+	if err := h.db.DB.Where("user_id = ?", userID).Order("id").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateAlertRule changes the threshold of a rule or enables/disables it
+func (h *AlertHandler) UpdateAlertRule(c *gin.Context) {
+	rule, ok := h.getOwnedAlertRule(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := prepareAlertRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.DB.Model(rule).Select("threshold", "enabled").Updates(rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteAlertRule removes a rule; alerts it already triggered are kept
+func (h *AlertHandler) DeleteAlertRule(c *gin.Context) {
+	rule, ok := h.getOwnedAlertRule(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DB.Delete(rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert rule deleted successfully"})
+}
+
+// ListAlerts lists triggered alerts, newest first. They can be filtered by
+// status, type and tracked URL.
+func (h *AlertHandler) ListAlerts(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	query := h.db.DB.Where("user_id = ?", userID)
+
+	if status := c.Query("status"); status != "" {
+		if status != models.AlertStatusOpen && status != models.AlertStatusAcknowledged && status != models.AlertStatusResolved {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open, acknowledged or resolved"})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+	if alertType := c.Query("type"); alertType != "" {
+		query = query.Where("type = ?", alertType)
+	}
+	if value := c.Query("tracked_url_id"); value != "" {
+		trackedURLID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tracked_url_id"})
+			return
+		}
+		query = query.Where("tracked_url_id = ?", trackedURLID)
+	}
+
+	alerts := make([]models.Alert, 0)
+	if err := query.Order("created_at DESC, id DESC").Find(&alerts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// AcknowledgeAlert marks an open alert as seen
+func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
+	alert, ok := h.getOwnedAlert(c)
+	if !ok {
+		return
+	}
+
+	if alert.Status != models.AlertStatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only open alerts can be acknowledged"})
+		return
+	}
+
+	now := time.Now()
+	alert.Status = models.AlertStatusAcknowledged
+	alert.AcknowledgedAt = &now
+	if err := h.db.DB.Model(alert).Select("status", "acknowledged_at").Updates(alert).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// ResolveAlert closes an open or acknowledged alert
+func (h *AlertHandler) ResolveAlert(c *gin.Context) {
+	alert, ok := h.getOwnedAlert(c)
+	if !ok {
+		return
+	}
+
+	if alert.Status == models.AlertStatusResolved {
+		c.JSON(http.StatusConflict, gin.H{"error": "Alert is already resolved"})
+		return
+	}
+
+	now := time.Now()
+	alert.Status = models.AlertStatusResolved
+	alert.ResolvedAt = &now
+	if err := h.db.DB.Model(alert).Select("status", "resolved_at").Updates(alert).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// prepareAlertRule validates the type and threshold of a rule
+func prepareAlertRule(rule *models.AlertRule) error {
+	if !services.AlertRuleTypes[rule.Type] {
+		return fmt.Errorf("unsupported alert rule type: %s", rule.Type)
+	}
+
+	if rule.Type != models.AlertRuleInternalLinksDrop {
+		rule.Threshold = 0
+		return nil
+	}
+	if rule.Threshold == 0 {
+		rule.Threshold = services.DefaultInternalLinksDropThreshold
+	}
+	if rule.Threshold <= 0 || rule.Threshold >= 100 {
+		return fmt.Errorf("threshold must be a percentage between 0 and 100")
+	}
+	return nil
+}
+
+// getOwnedAlertRule loads the alert rule referenced by the :id URL parameter
+// and verifies that it belongs to the authenticated user. On failure the
+// error response has already been written and false is returned.
+func (h *AlertHandler) getOwnedAlertRule(c *gin.Context) (*models.AlertRule, bool) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert rule ID format"})
+		return nil, false
+	}
+
+	var rule models.AlertRule
+	if err := h.db.DB.First(&rule, "id = ?", ruleID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return nil, false
+	}
+
+	if rule.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this alert rule"})
+		return nil, false
+	}
+
+	return &rule, true
+}
+
+// getOwnedAlert loads the alert referenced by the :id URL parameter and
+// verifies that it belongs to the authenticated user
+func (h *AlertHandler) getOwnedAlert(c *gin.Context) (*models.Alert, bool) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID format"})
+		return nil, false
+	}
+
+	var alert models.Alert
+	if err := h.db.DB.First(&alert, "id = ?", alertID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return nil, false
+	}
+
+	if alert.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this alert"})
+		return nil, false
+	}
+
+	return &alert, true
+}
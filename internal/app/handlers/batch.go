@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/ayeshakhan-29/test-task-BE/internal/database"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxBatchUploadSize limits the size of a batch submission body
+const maxBatchUploadSize = 10 << 20
+
+type BatchHandler struct {
+	db      *database.Database
+	batches *services.BatchRunner
+	urls    *services.URLCanonicalizer
+}
+
+func NewBatchHandler(db *database.Database, svc *services.Services) *BatchHandler {
+	return &BatchHandler{db: db, batches: svc.Batches, urls: svc.URLs}
+}
+
+// SubmitBatch accepts a list of URLs as a JSON array (or {"urls": [...]}),
+// newline-separated text, or a multipart CSV upload in the "file" field with
+// a URL column ("url" unless the "column" form field names another one).
+// Invalid and duplicate URLs are dropped and reported.
+func (h *BatchHandler) SubmitBatch(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchUploadSize)
+	values, err := readBatchURLs(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	urls, rejected, duplicates := services.CleanBatchURLs(values, h.urls)
+	batch, err := h.batches.Submit(userID.(uint64), urls)
+	if err != nil {
+		var tooLarge *services.BatchTooLargeError
+		switch {
+		case errors.Is(err, services.ErrEmptyBatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "rejected": rejected})
+		case errors.As(err, &tooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create batch"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.BatchSubmitResponse{
+		Batch:      *batch,
+		Rejected:   rejected,
+		Duplicates: duplicates,
+	})
+}
+
+// ListBatches lists the user's batches, newest first
+func (h *BatchHandler) ListBatches(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	batches := make([]models.Batch, 0)
+	if err := h.db.DB.Where("user_id = ?", userID).Order("id DESC").Find(&batches).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch batches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// GetBatch returns the progress of a batch
+func (h *BatchHandler) GetBatch(c *gin.Context) {
+	batch, ok := h.getOwnedBatch(c)
+	if !ok {
+		return
+	}
+
+	var counts []struct {
+		Status string
+		Count  int
+	}
+	if err := h.db.DB.Model(&models.BatchItem{}).
+		Select("status, COUNT(*) AS count").
+		Where("batch_id = ?", batch.ID).
+		Group("status").
+		Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch batch progress"})
+		return
+	}
+
+	response := models.BatchProgressResponse{Batch: *batch}
+	for _, count := range counts {
+		switch count.Status {
+		case models.BatchItemPending:
+			response.Pending = count.Count
+		case models.BatchItemRunning:
+			response.Running = count.Count
+		}
+	}
+	if batch.Total > 0 {
+		response.Progress = float64(batch.Completed+batch.Failed) / float64(batch.Total)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBatchResults lists the items of a batch with the results of their crawls
+func (h *BatchHandler) GetBatchResults(c *gin.Context) {
+	batch, ok := h.getOwnedBatch(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.loadBatchResults(batch.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch batch results"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.BatchResultsResponse{Batch: *batch, Items: items})
+}
+
+// GetBatchReport downloads the combined results of a completed batch as CSV
+func (h *BatchHandler) GetBatchReport(c *gin.Context) {
+	batch, ok := h.getOwnedBatch(c)
+	if !ok {
+		return
+	}
+
+	if batch.Status != models.BatchStatusCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Batch is still running"})
+		return
+	}
+
+	items, err := h.loadBatchResults(batch.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch batch results"})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="batch-%d.csv"`, batch.ID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"url", "status", "error", "crawl_id", "page_title", "html_version",
+		"h1", "h2", "h3", "h4", "h5", "h6",
+		"internal_links", "external_links", "inaccessible_links", "has_login_form",
+		"word_count", "readability_score", "language", "cookies", "insecure_cookies", "health_score",
+	})
+	for _, item := range items {
+		row := []string{item.URL, item.Status, item.Error, "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", ""}
+		if item.CrawlID != nil {
+			row[3] = strconv.FormatUint(uint64(*item.CrawlID), 10)
+		}
+		if r := item.Result; r != nil {
+			row[4] = r.PageTitle
+			row[5] = r.HTMLVersion
+			row[6] = strconv.Itoa(r.Headings.H1)
+			row[7] = strconv.Itoa(r.Headings.H2)
+			row[8] = strconv.Itoa(r.Headings.H3)
+			row[9] = strconv.Itoa(r.Headings.H4)
+			row[10] = strconv.Itoa(r.Headings.H5)
+			row[11] = strconv.Itoa(r.Headings.H6)
+			row[12] = strconv.Itoa(r.InternalLinks)
+			row[13] = strconv.Itoa(r.ExternalLinks)
+			row[14] = strconv.Itoa(len(r.InaccessibleLinks))
+			row[15] = strconv.FormatBool(r.HasLoginForm)
+			row[16] = strconv.Itoa(r.WordCount)
+			row[17] = strconv.FormatFloat(r.ReadabilityScore, 'f', 1, 64)
+			row[18] = r.Language
+			row[19] = strconv.Itoa(r.Cookies.Total)
+			row[20] = strconv.Itoa(r.Cookies.Insecure)
+			if r.HealthScore != nil {
+				row[21] = strconv.FormatFloat(*r.HealthScore, 'f', 1, 64)
+			}
+		}
+		w.Write(row)
+	}
+	w.Flush()
+}
+
+// loadBatchResults returns the items of a batch with the summaries of the
+// completed crawls they produced
+func (h *BatchHandler) loadBatchResults(batchID uint) ([]models.BatchItemResult, error) {
+	var items []models.BatchItem
+	if err := h.db.DB.Where("batch_id = ?", batchID).Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	var crawlIDs []uint
+	for _, item := range items {
+		if item.CrawlID != nil && item.Status == models.BatchItemCompleted {
+			crawlIDs = append(crawlIDs, *item.CrawlID)
+		}
+	}
+
+	crawls := make(map[uint]models.CrawlResult, len(crawlIDs))
+	if len(crawlIDs) > 0 {
+		var found []models.CrawlResult
+		if err := h.db.DB.Where("id IN ?", crawlIDs).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		for _, crawl := range found {
+			crawls[crawl.ID] = crawl
+		}
+	}
+
+	results := make([]models.BatchItemResult, len(items))
+	for i, item := range items {
+		results[i] = models.BatchItemResult{BatchItem: item}
+		if item.CrawlID == nil {
+			continue
+		}
+		// The run may have been pruned or deleted since
+		if crawl, ok := crawls[*item.CrawlID]; ok {
+			summary := toCrawlListResponse(crawl)
+			results[i].Result = &summary
+		}
+	}
+	return results, nil
+}
+
+// readBatchURLs extracts the submitted URLs according to the content type
+func readBatchURLs(c *gin.Context) ([]string, error) {
+	switch c.ContentType() {
+	case "application/json":
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil, err
+		}
+		var urls []string
+		if err := json.Unmarshal(body, &urls); err == nil {
+			return urls, nil
+		}
+		var wrapped struct {
+			URLs []string `json:"urls"`
+		}
+		if err := json.Unmarshal(body, &wrapped); err != nil {
+			return nil, errors.New("expected a JSON array of URLs or an object with a urls array")
+		}
+		return wrapped.URLs, nil
+
+	case "multipart/form-data":
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("missing CSV file in the file field")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return services.ParseURLCSV(file, c.PostForm("column"))
+
+	default:
+		return services.ParseURLLines(c.Request.Body)
+	}
+}
+
+// getOwnedBatch loads the batch referenced by the :id URL parameter and
+// verifies that it belongs to the authenticated user. On failure the error
+// response has already been written and false is returned.
+func (h *BatchHandler) getOwnedBatch(c *gin.Context) (*models.Batch, bool) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	batchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID format"})
+		return nil, false
+	}
+
+	var batch models.Batch
+	if err := h.db.DB.First(&batch, "id = ?", batchID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Batch not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return nil, false
+	}
+
+	if batch.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this batch"})
+		return nil, false
+	}
+
+	return &batch, true
+}
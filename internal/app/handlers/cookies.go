@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCrawlCookies returns the cookies set while fetching a crawl and the
+// insecure attributes flagged on them
+func (h *CrawlHandler) GetCrawlCookies(c *gin.Context) {
+	crawl, ok := h.getOwnedCrawl(c)
+	if !ok {
+		return
+	}
+
+	report := crawl.Cookies
+	if report.Summary.ByIssue == nil {
+		report.Summary.ByIssue = make(map[string]int)
+	}
+	if report.Cookies == nil {
+		report.Cookies = make([]models.ResponseCookie, 0)
+	}
+
+	c.JSON(http.StatusOK, models.CookiesResponse{
+		CrawlID:      crawl.ID,
+		URL:          crawl.URL,
+		CookieReport: report,
+	})
+}
@@ -1,18 +1,17 @@
 package handlers
 
 import (
-	"bytes"
-	"io/ioutil"
+	"errors"
+	"fmt"
 	"net/http"
-	"net/url"
 	"strconv"
-	"strings"
+	"time"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/ayeshakhan-29/test-task-BE/internal/config"
 	"github.com/ayeshakhan-29/test-task-BE/internal/database"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/net/html"
 	"gorm.io/gorm"
 )
 
@@ -24,30 +23,26 @@ func (h *CrawlHandler) ListCrawls(c *gin.Context) {
 		return
 	}
 
+	// Only the latest run of every tracked URL is listed; older runs are
+	// available through the run history
+	query, err := applyCrawlFilters(h.db.DB.Where("user_id = ? AND id IN (?)", userID, services.LatestRunIDs(h.db.DB, userID)), c)
+	if err == nil {
+		query, err = applyCrawlSort(query, c)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var crawls []models.CrawlResult
-	if err := h.db.DB.Where("user_id = ?", userID).Find(&crawls).Error; err != nil {
+	if err := query.Find(&crawls).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl results"})
 		return
 	}
 
 	response := make([]models.CrawlListResponse, 0, len(crawls))
 	for _, crawl := range crawls {
-		// Convert the JSON value to a slice if it's a number
-		if len(crawl.InaccessibleLinks) == 0 {
-			crawl.InaccessibleLinks = make(models.StringSlice, 0)
-		}
-		response = append(response, models.CrawlListResponse{
-			ID:              crawl.ID,
-			URL:             crawl.URL,
-			PageTitle:       crawl.PageTitle,
-			CreatedAt:       crawl.CreatedAt,
-			HTMLVersion:     crawl.HTMLVersion,
-			Headings:        crawl.Headings,
-			InternalLinks:   crawl.InternalLinks,
-			ExternalLinks:   crawl.ExternalLinks,
-			InaccessibleLinks: crawl.InaccessibleLinks,
-			HasLoginForm:    crawl.HasLoginForm,
-		})
+		response = append(response, toCrawlListResponse(crawl))
 	}
 	c.JSON(http.StatusOK, response)
 }
@@ -91,21 +86,67 @@ func (h *CrawlHandler) GetCrawlByID(c *gin.Context) {
 		return
 	}
 
-	// Convert to response format
-	response := models.CrawlListResponse{
+	c.JSON(http.StatusOK, toCrawlListResponse(crawl))
+}
+
+// toCrawlListResponse converts a crawl run to its list/detail response format
+func toCrawlListResponse(crawl models.CrawlResult) models.CrawlListResponse {
+	// Convert the JSON value to a slice if it's a number
+	if len(crawl.InaccessibleLinks) == 0 {
+		crawl.InaccessibleLinks = make(models.StringSlice, 0)
+	}
+	if crawl.Links.Schemes == nil {
+		crawl.Links.Schemes = make(map[string]int)
+	}
+	if crawl.Links.BrokenAnchors == nil {
+		crawl.Links.BrokenAnchors = make([]string, 0)
+	}
+	if crawl.Technologies == nil {
+		crawl.Technologies = make(models.TechnologyList, 0)
+	}
+	if crawl.ThirdParties.Summary.ByCategory == nil {
+		crawl.ThirdParties.Summary.ByCategory = make(map[string]int)
+	}
+	if crawl.Cookies.Summary.ByIssue == nil {
+		crawl.Cookies.Summary.ByIssue = make(map[string]int)
+	}
+	if crawl.ScoreBreakdown.Items == nil {
+		crawl.ScoreBreakdown.Items = make([]models.ScoreItem, 0)
+	}
+	if crawl.Markup.Summary.ByType == nil {
+		crawl.Markup.Summary.ByType = make(map[string]int)
+	}
+
+	return models.CrawlListResponse{
 		ID:              crawl.ID,
+		Status:          crawl.Status,
+		Error:           crawl.Error,
+		TrackedURLID:    crawl.TrackedURLID,
+		Pinned:          crawl.Pinned,
 		URL:             crawl.URL,
+		SourceType:      crawl.SourceType,
 		PageTitle:       crawl.PageTitle,
 		CreatedAt:       crawl.CreatedAt,
 		HTMLVersion:     crawl.HTMLVersion,
+		Doctype:         crawl.Doctype,
+		Markup:          crawl.Markup.Summary,
 		Headings:        crawl.Headings,
 		InternalLinks:   crawl.InternalLinks,
 		ExternalLinks:   crawl.ExternalLinks,
 		InaccessibleLinks: crawl.InaccessibleLinks,
+		LinkSchemes:     crawl.Links.Schemes,
+		BrokenAnchors:   crawl.Links.BrokenAnchors,
 		HasLoginForm:    crawl.HasLoginForm,
+		Technologies:    crawl.Technologies,
+		ThirdParties:    crawl.ThirdParties.Summary,
+		Cookies:         crawl.Cookies.Summary,
+		HealthScore:     crawl.HealthScore,
+		ScoreBreakdown:  crawl.ScoreBreakdown,
+		WordCount:       crawl.WordCount,
+		ReadabilityScore: crawl.ReadabilityScore,
+		Language:        crawl.Language,
+		LanguageMismatch: crawl.LanguageMismatch,
 	}
-
-	c.JSON(http.StatusOK, response)
 }
 
 func (h *CrawlHandler) DeleteCrawl(c *gin.Context) {
@@ -140,8 +181,12 @@ func (h *CrawlHandler) DeleteCrawl(c *gin.Context) {
 		return
 	}
 
-	// Delete the crawl
-	if err := h.db.DB.Delete(&crawl).Error; err != nil {
+	// Delete the crawl together with the rest of its URL's run history.
+	// Individual runs can be deleted through the run history endpoints.
+	if err := h.db.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := deleteTrackedURLs(tx, []uint{crawl.ID})
+		return err
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete crawl"})
 		return
 	}
@@ -149,12 +194,66 @@ func (h *CrawlHandler) DeleteCrawl(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Crawl deleted successfully"})
 }
 
+// syncCrawlMargin is the part of the server's write timeout kept for writing
+// the response of a synchronous crawl
+const syncCrawlMargin = 5 * time.Second
+
 type CrawlHandler struct {
-	db *database.Database
+	db        *database.Database
+	cfg       *config.Config
+	snapshots *services.SnapshotService
+	crawler   *services.Crawler
+	queue     *services.CrawlQueue
+	progress  *services.ProgressHub
+	urls      *services.URLCanonicalizer
 }
 
-func NewCrawlHandler(db *database.Database) *CrawlHandler {
-	return &CrawlHandler{db: db}
+func NewCrawlHandler(db *database.Database, cfg *config.Config, svc *services.Services) *CrawlHandler {
+	return &CrawlHandler{
+		db:        db,
+		cfg:       cfg,
+		snapshots: svc.Snapshots,
+		crawler:   svc.Crawler,
+		queue:     svc.Queue,
+		progress:  svc.Progress,
+		urls:      svc.URLs,
+	}
+}
+
+// getOwnedCrawl loads the crawl referenced by the :id URL parameter and
+// verifies that it belongs to the authenticated user. On failure the error
+// response has already been written and false is returned.
+func (h *CrawlHandler) getOwnedCrawl(c *gin.Context) (*models.CrawlResult, bool) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	crawlID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid crawl ID format"})
+		return nil, false
+	}
+
+	var crawl models.CrawlResult
+	if err := h.db.DB.First(&crawl, "id = ?", crawlID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Crawl not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return nil, false
+	}
+
+	// Check if user owns this crawl
+	if crawl.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to view this crawl"})
+		return nil, false
+	}
+
+	return &crawl, true
 }
 
 func (h *CrawlHandler) BulkDeleteCrawls(c *gin.Context) {
@@ -210,15 +309,15 @@ func (h *CrawlHandler) BulkDeleteCrawls(c *gin.Context) {
 		return
 	}
 
-	// Delete the records
-	result := db.Where("id IN (?) AND user_id = ?", req.IDs, userID).Delete(&models.CrawlResult{})
-	if result.Error != nil {
+	// Delete the records along with the run history of their URLs
+	deletedCount, err := deleteTrackedURLs(db, req.IDs)
+	if err != nil {
 		db.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete crawls"})
 		return
 	}
 
-	if result.RowsAffected == 0 {
+	if deletedCount == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"message": "No crawls found to delete"})
 		return
 	}
@@ -230,10 +329,13 @@ func (h *CrawlHandler) BulkDeleteCrawls(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Crawls deleted successfully",
-		"deleted_count": result.RowsAffected,
+		"deleted_count": deletedCount,
 	})
 }
 
+// CrawlURL crawls a URL and responds with the stored result. With async=true
+// it responds at once with 202 and the crawl ID; synchronous crawls that do
+// not finish before the server's write timeout are answered the same way.
 func (h *CrawlHandler) CrawlURL(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -248,199 +350,146 @@ func (h *CrawlHandler) CrawlURL(c *gin.Context) {
 	}
 
 	debug := c.Query("debug") == "true"
+	opts := models.CrawlOptions{FreshLinkChecks: c.Query("fresh") == "true"}
 
-	parsedURL, err := url.ParseRequestURI(req.URL)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL"})
+	if c.Query("async") == "true" {
+		h.crawlAsync(c, userID.(uint64), req.URL, opts)
 		return
 	}
 
-	// Fetch the page
-	resp, err := http.Get(parsedURL.String())
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to fetch URL: " + err.Error()})
+	// The crawl runs on the queue, as waiting out the politeness delays of
+	// its hosts can take longer than the server's write timeout. A crawl that
+	// does not finish in time is answered like an async one and keeps running.
+	done := make(chan crawlOutcome, 1)
+	response, ok := h.queueCrawl(c, userID.(uint64), req.URL, opts, done)
+	if !ok {
 		return
 	}
-	defer resp.Body.Close()
-
-	// Read body for debug mode
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response body"})
+	result, ok := h.awaitCrawl(c, response, done)
+	if !ok {
 		return
 	}
-
-	// Create a fresh reader for goquery
-	resp.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-
-	// Parse the HTML
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	output, err := result.output, result.err
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse HTML"})
-		return
-	}
-
-	// Extract data
-	result := models.CrawlResult{
-		URL:    req.URL,
-		UserID: userID.(uint64),
-		HTMLVersion: func() string {
-			version, err := extractHTMLVersionFromURL(req.URL)
-			if err != nil {
-				return ""
-			}
-			return version
-		}(),
-		PageTitle:   doc.Find("title").Text(),
-	}
-
-	// Count headings
-	headings := models.HeadingCounts{
-		H1: doc.Find("h1").Length(),
-		H2: doc.Find("h2").Length(),
-		H3: doc.Find("h3").Length(),
-		H4: doc.Find("h4").Length(),
-		H5: doc.Find("h5").Length(),
-		H6: doc.Find("h6").Length(),
-	}
-	result.Headings = headings
-
-	// Count links and get broken links
-	internalLinks, externalLinks, _, brokenLinks := countLinks(doc, parsedURL.Hostname())
-	result.InternalLinks = internalLinks
-	result.ExternalLinks = externalLinks
-	result.InaccessibleLinks = brokenLinks
-
-	// Check for login form
-	result.HasLoginForm = hasLoginForm(doc)
-
-	// Check if a crawl entry with the same URL and user ID already exists
-	var existingCrawl models.CrawlResult
-	err = h.db.DB.Where("url = ? AND user_id = ?", req.URL, userID).First(&existingCrawl).Error
-
-	if err == nil {
-		// Update existing crawl
-		result.ID = existingCrawl.ID
-		result.CreatedAt = existingCrawl.CreatedAt // Preserve original creation time
-		if err := h.db.DB.Save(&result).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update crawl result"})
-			return
-		}
-	} else if err == gorm.ErrRecordNotFound {
-		// Create new crawl if not exists
-		if err := h.db.DB.Create(&result).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save crawl result"})
-			return
+		var fetchErr *services.FetchError
+		switch {
+		case errors.Is(err, services.ErrInvalidURL):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL"})
+		case errors.As(err, &fetchErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to fetch URL: " + fetchErr.Err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to crawl URL: " + err.Error()})
 		}
-	} else {
-		// Handle other database errors
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
 		return
 	}
 
 	// Return result with HTML if in debug mode
 	if debug {
 		c.JSON(http.StatusOK, gin.H{
-			"result": result,
-			"html":   string(body),
+			"result": output.Result,
+			"html":   string(output.HTML),
 		})
 	} else {
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusOK, output.Result)
 	}
 }
 
-func extractHTMLVersionFromURL(url string) (string, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	z := html.NewTokenizer(resp.Body)
-	for {
-		tt := z.Next()
-		switch tt {
-		case html.ErrorToken:
-			return "Unknown", nil // No doctype found
-		case html.DoctypeToken:
-			doctype := strings.ToLower(string(z.Text()))
-			switch {
-			case doctype == "html":
-				return "HTML5", nil
-			case strings.Contains(doctype, "xhtml 1.0 strict"):
-				return "XHTML 1.0 Strict", nil
-			case strings.Contains(doctype, "xhtml 1.0 transitional"):
-				return "XHTML 1.0 Transitional", nil
-			case strings.Contains(doctype, "xhtml 1.0 frameset"):
-				return "XHTML 1.0 Frameset", nil
-			case strings.Contains(doctype, "xhtml 1.1"):
-				return "XHTML 1.1", nil
-			case strings.Contains(doctype, "html 4.01 transitional"):
-				return "HTML 4.01 Transitional", nil
-			case strings.Contains(doctype, "html 4.01 frameset"):
-				return "HTML 4.01 Frameset", nil
-			case strings.Contains(doctype, "html 4.01"):
-				return "HTML 4.01", nil
-			case strings.Contains(doctype, "html 4.0"):
-				return "HTML 4.0", nil
-			case strings.Contains(doctype, "html 3.2"):
-				return "HTML 3.2", nil
-			case strings.Contains(doctype, "html 2.0"):
-				return "HTML 2.0", nil
-			default:
-				return "Unknown", nil
-			}
-		}
+// crawlAsync stores a running crawl, queues it and responds immediately with
+// the crawl ID; progress is streamed by GET /crawls/:id/events
+func (h *CrawlHandler) crawlAsync(c *gin.Context, userID uint64, rawURL string, opts models.CrawlOptions) {
+	response, ok := h.queueCrawl(c, userID, rawURL, opts, nil)
+	if !ok {
+		return
 	}
+
+	c.JSON(http.StatusAccepted, response)
 }
 
-func countLinks(doc *goquery.Document, hostname string) (int, int, int, []string) {
-	internal, external, inaccessible := 0, 0, 0
-	var brokenLinks []string
+// crawlOutcome is the outcome of a queued crawl
+type crawlOutcome struct {
+	output *services.CrawlOutput
+	err    error
+}
 
-	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
-		href, _ := s.Attr("href")
-		if href == "" || strings.HasPrefix(href, "#") {
-			return
+// queueCrawl stores a running crawl and queues it. done, if set, receives
+// the outcome once the crawl has finished. On failure the error response has
+// already been written and false is returned.
+func (h *CrawlHandler) queueCrawl(c *gin.Context, userID uint64, rawURL string, opts models.CrawlOptions, done chan<- crawlOutcome) (models.AsyncCrawlResponse, bool) {
+	run, err := h.crawler.Begin(c.Request.Context(), userID, rawURL, opts)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidURL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL"})
+			return models.AsyncCrawlResponse{}, false
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to crawl URL: " + err.Error()})
+		return models.AsyncCrawlResponse{}, false
+	}
 
-		// Check if link is accessible
-		resp, err := http.Head(href)
-		if err != nil || resp.StatusCode >= 400 {
-			brokenLinks = append(brokenLinks, href)
-			inaccessible++
-			return
-		}
+	return h.enqueueRun(c, services.CrawlJob{UserID: userID, URL: rawURL, Run: run}, done)
+}
 
-		if strings.HasPrefix(href, "http") {
-			if strings.Contains(href, hostname) {
-				internal++
-			} else {
-				external++
-			}
-		} else {
-			internal++
+// enqueueRun queues the crawl of a stored run and returns the response
+// describing it. done, if set, receives the outcome once the crawl has
+// finished. On failure the error response has already been written and
+// false is returned.
+func (h *CrawlHandler) enqueueRun(c *gin.Context, job services.CrawlJob, done chan<- crawlOutcome) (models.AsyncCrawlResponse, bool) {
+	run := job.Run
+	// Built before queueing since the worker updates run while crawling
+	response := models.AsyncCrawlResponse{
+		ID:           run.ID,
+		TrackedURLID: run.TrackedURLID,
+		URL:          run.URL,
+		Status:       run.Status,
+		EventsURL:    fmt.Sprintf("/api/v1/crawls/%d/events", run.ID),
+	}
+
+	if done != nil {
+		job.Done = func(output *services.CrawlOutput, err error) {
+			done <- crawlOutcome{output, err}
 		}
-	})
+	}
+	if err := h.queue.Enqueue(job); err != nil {
+		h.crawler.Fail(run, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Crawl queue is full, try again later"})
+		return models.AsyncCrawlResponse{}, false
+	}
 
-	return internal, external, inaccessible, brokenLinks
+	return response, true
 }
 
-func hasLoginForm(doc *goquery.Document) bool {
-	// Check for common login form indicators
-	loginSelectors := []string{
-		"input[type='password']",
-		"form[action*='login']",
-		"form[action*='signin']",
-		"#login-form",
-		".login-form",
+// awaitCrawl waits for a queued crawl to finish. A crawl that does not
+// finish within syncCrawlWait is answered with response, like an async one,
+// and keeps running. false is returned when the response has already been
+// written or the client has gone away.
+func (h *CrawlHandler) awaitCrawl(c *gin.Context, response models.AsyncCrawlResponse, done <-chan crawlOutcome) (crawlOutcome, bool) {
+	var timeout <-chan time.Time
+	if wait := h.syncCrawlWait(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case result := <-done:
+		return result, true
+	case <-timeout:
+		c.JSON(http.StatusAccepted, response)
+		return crawlOutcome{}, false
+	case <-c.Request.Context().Done():
+		return crawlOutcome{}, false
 	}
+}
 
-	for _, selector := range loginSelectors {
-		if doc.Find(selector).Length() > 0 {
-			return true
-		}
+// syncCrawlWait is how long a synchronous crawl request waits for the crawl
+// to finish, leaving time to write the response before the server's write
+// timeout. 0 waits as long as it takes.
+func (h *CrawlHandler) syncCrawlWait() time.Duration {
+	timeout := h.cfg.Server.WriteTimeout
+	if timeout <= 0 {
+		return 0
 	}
-
-	return false
+	if timeout > 2*syncCrawlMargin {
+		return timeout - syncCrawlMargin
+	}
+	return timeout / 2
 }
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// applyCrawlFilters narrows a crawl_results query using the optional list
+// filters passed as query parameters
+func applyCrawlFilters(query *gorm.DB, c *gin.Context) (*gorm.DB, error) {
+	if lang := strings.ToLower(strings.TrimSpace(c.Query("language"))); lang != "" {
+		query = query.Where("language = ?", lang)
+	}
+
+	if source := strings.ToLower(strings.TrimSpace(c.Query("source_type"))); source != "" {
+		query = query.Where("source_type = ?", source)
+	}
+
+	// technology may list several comma-separated names, all of which must
+	// have been detected
+	for _, tech := range strings.Split(c.Query("technology"), ",") {
+		tech = strings.ToLower(strings.TrimSpace(tech))
+		if tech == "" {
+			continue
+		}
+		query = query.Where("technology_names LIKE ?", "%,"+escapeLike(tech)+",%")
+	}
+
+	if v := c.Query("language_mismatch"); v != "" {
+		mismatch, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid language_mismatch value: %s", v)
+		}
+		query = query.Where("language_mismatch = ?", mismatch)
+	}
+
+	intFilters := []struct {
+		param  string
+		clause string
+	}{
+		{"min_words", "word_count >= ?"},
+		{"max_words", "word_count <= ?"},
+	}
+	for _, f := range intFilters {
+		v := c.Query(f.param)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %s", f.param, v)
+		}
+		query = query.Where(f.clause, n)
+	}
+
+	floatFilters := []struct {
+		param  string
+		clause string
+	}{
+		{"min_readability", "readability_score >= ?"},
+		{"max_readability", "readability_score <= ?"},
+		{"min_score", "health_score >= ?"},
+		{"max_score", "health_score <= ?"},
+	}
+	for _, f := range floatFilters {
+		v := c.Query(f.param)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %s", f.param, v)
+		}
+		query = query.Where(f.clause, n)
+	}
+
+	return query, nil
+}
+
+// crawlSortColumns maps the accepted sort keys of the crawl list to columns
+var crawlSortColumns = map[string]string{
+	"score":       "health_score",
+	"created_at":  "created_at",
+	"word_count":  "word_count",
+	"readability": "readability_score",
+}
+
+// applyCrawlSort orders a crawl_results query by the optional sort query
+// parameter, a sort key prefixed with "-" for descending order
+func applyCrawlSort(query *gorm.DB, c *gin.Context) (*gorm.DB, error) {
+	sortBy := strings.TrimSpace(c.Query("sort"))
+	if sortBy == "" {
+		return query, nil
+	}
+
+	direction := "ASC"
+	if key, ok := strings.CutPrefix(sortBy, "-"); ok {
+		sortBy = key
+		direction = "DESC"
+	}
+	column, ok := crawlSortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort value: %s", c.Query("sort"))
+	}
+	return query.Order(column + " " + direction).Order("id " + direction), nil
+}
+
+// escapeLike escapes the LIKE wildcards of a value matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
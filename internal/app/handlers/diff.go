@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/gin-gonic/gin"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+// in the HTML diff
+const diffContextLines = 3
+
+// GetCrawlDiff returns the structured difference between two crawls of the
+// same URL. With html=true a unified diff of the stored HTML is included
+// when both crawls have a snapshot.
+func (h *CrawlHandler) GetCrawlDiff(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	fromID, err := strconv.ParseUint(c.Query("from"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing from crawl ID"})
+		return
+	}
+	toID, err := strconv.ParseUint(c.Query("to"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing to crawl ID"})
+		return
+	}
+
+	var crawls []models.CrawlResult
+	if err := h.db.DB.Where("id IN ? AND user_id = ?", []uint64{fromID, toID}, userID).Find(&crawls).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl results"})
+		return
+	}
+
+	var from, to *models.CrawlResult
+	for i := range crawls {
+		if uint64(crawls[i].ID) == fromID {
+			from = &crawls[i]
+		}
+		if uint64(crawls[i].ID) == toID {
+			to = &crawls[i]
+		}
+	}
+	if from == nil || to == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Crawl not found"})
+		return
+	}
+
+	if from.TrackedURLID != to.TrackedURLID || from.URL != to.URL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Crawls must be of the same URL"})
+		return
+	}
+
+	if from.Status != models.CrawlStatusCompleted || to.Status != models.CrawlStatusCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only completed crawls can be compared"})
+		return
+	}
+
+	diff := services.DiffCrawls(*from, *to)
+
+	if c.Query("html") == "true" {
+		if from.SnapshotHash == "" || to.SnapshotHash == "" {
+			diff.HTMLDiffError = "HTML snapshots are not available for both crawls"
+		} else {
+			htmlDiff, err := h.snapshotDiff(c.Request.Context(), from, to)
+			if err != nil {
+				diff.HTMLDiffError = err.Error()
+			} else {
+				diff.HTMLDiff = &htmlDiff
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// snapshotDiff builds a unified diff of the HTML snapshots of two crawls
+func (h *CrawlHandler) snapshotDiff(ctx context.Context, from, to *models.CrawlResult) (string, error) {
+	if from.SnapshotHash == to.SnapshotHash {
+		return "", nil
+	}
+
+	fromHTML, err := h.readSnapshot(ctx, from.SnapshotHash)
+	if err != nil {
+		return "", err
+	}
+	toHTML, err := h.readSnapshot(ctx, to.SnapshotHash)
+	if err != nil {
+		return "", err
+	}
+
+	return services.UnifiedDiff(
+		fmt.Sprintf("crawl-%d.html", from.ID),
+		fmt.Sprintf("crawl-%d.html", to.ID),
+		fromHTML, toHTML, diffContextLines,
+	)
+}
+
+func (h *CrawlHandler) readSnapshot(ctx context.Context, hash string) (string, error) {
+	reader, err := h.snapshots.Open(ctx, hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCrawlForms returns the form inventory of a crawl
+func (h *CrawlHandler) GetCrawlForms(c *gin.Context) {
+	crawl, ok := h.getOwnedCrawl(c)
+	if !ok {
+		return
+	}
+
+	forms := []models.Form(crawl.Forms)
+	if forms == nil {
+		forms = make([]models.Form, 0)
+	}
+
+	c.JSON(http.StatusOK, models.FormsResponse{
+		CrawlID: crawl.ID,
+		URL:     crawl.URL,
+		Count:   len(forms),
+		Forms:   forms,
+	})
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListTrackedURLs lists the URLs the user has crawled with a summary of their latest run
+func (h *CrawlHandler) ListTrackedURLs(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var tracked []models.TrackedURL
+	if err := h.db.DB.Where("user_id = ?", userID).Order("last_crawled_at DESC").Find(&tracked).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracked URLs"})
+		return
+	}
+
+	var latestRuns []models.CrawlResult
+	if err := h.db.DB.Where("id IN (?)", services.LatestRunIDs(h.db.DB, userID)).Find(&latestRuns).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl results"})
+		return
+	}
+	runsByID := make(map[uint]models.CrawlResult, len(latestRuns))
+	for _, run := range latestRuns {
+		runsByID[run.ID] = run
+	}
+
+	response := make([]models.TrackedURLResponse, 0, len(tracked))
+	for _, t := range tracked {
+		var latest *models.CrawlResult
+		if t.LatestRunID != nil {
+			if run, ok := runsByID[*t.LatestRunID]; ok {
+				latest = &run
+			}
+		}
+		response = append(response, toTrackedURLResponse(t, latest))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRunHistory lists every stored run of a tracked URL, newest first
+func (h *CrawlHandler) GetRunHistory(c *gin.Context) {
+	tracked, ok := h.getOwnedTrackedURL(c)
+	if !ok {
+		return
+	}
+
+	var runs []models.CrawlResult
+	if err := h.db.DB.Where("tracked_url_id = ?", tracked.ID).Order("id DESC").Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl runs"})
+		return
+	}
+
+	response := models.RunHistoryResponse{
+		Runs: make([]models.CrawlListResponse, 0, len(runs)),
+	}
+	var latest *models.CrawlResult
+	for i, run := range runs {
+		if tracked.LatestRunID != nil && run.ID == *tracked.LatestRunID {
+			latest = &runs[i]
+		}
+		response.Runs = append(response.Runs, toCrawlListResponse(run))
+	}
+	response.TrackedURL = toTrackedURLResponse(*tracked, latest)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateRun pins or unpins a run. Pinned runs are exempt from history pruning
+// and cannot be deleted until they are unpinned.
+func (h *CrawlHandler) UpdateRun(c *gin.Context) {
+	tracked, ok := h.getOwnedTrackedURL(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	run, ok := h.getTrackedURLRun(c, tracked)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DB.Model(run).Update("pinned", *req.Pinned).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update run"})
+		return
+	}
+	run.Pinned = *req.Pinned
+
+	c.JSON(http.StatusOK, toCrawlListResponse(*run))
+}
+
+// DeleteRun deletes a single run from the history of a tracked URL
+func (h *CrawlHandler) DeleteRun(c *gin.Context) {
+	tracked, ok := h.getOwnedTrackedURL(c)
+	if !ok {
+		return
+	}
+
+	run, ok := h.getTrackedURLRun(c, tracked)
+	if !ok {
+		return
+	}
+
+	if run.Pinned {
+		c.JSON(http.StatusConflict, gin.H{"error": "Run is pinned; unpin it before deleting"})
+		return
+	}
+
+	if err := h.db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.DeleteLinkEdges(tx, []uint{run.ID}); err != nil {
+			return err
+		}
+		if err := tx.Delete(run).Error; err != nil {
+			return err
+		}
+		return services.RefreshTrackedURL(tx, tracked.ID)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete run"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Run deleted successfully"})
+}
+
+// getOwnedTrackedURL loads the tracked URL referenced by the :id URL parameter
+// and verifies that it belongs to the authenticated user. On failure the
+// error response has already been written and false is returned.
+func (h *CrawlHandler) getOwnedTrackedURL(c *gin.Context) (*models.TrackedURL, bool) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	trackedID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID format"})
+		return nil, false
+	}
+
+	var tracked models.TrackedURL
+	if err := h.db.DB.First(&tracked, "id = ?", trackedID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return nil, false
+	}
+
+	if tracked.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this URL"})
+		return nil, false
+	}
+
+	return &tracked, true
+}
+
+// getTrackedURLRun loads the run referenced by the :run_id URL parameter,
+// which must belong to the given tracked URL
+func (h *CrawlHandler) getTrackedURLRun(c *gin.Context, tracked *models.TrackedURL) (*models.CrawlResult, bool) {
+	runID, err := strconv.ParseUint(c.Param("run_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID format"})
+		return nil, false
+	}
+
+	var run models.CrawlResult
+	if err := h.db.DB.First(&run, "id = ? AND tracked_url_id = ?", runID, tracked.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return nil, false
+	}
+
+	return &run, true
+}
+
+// deleteTrackedURLs deletes the tracked URLs of the given crawl runs together
+// with all of their runs, alert rules and schedules and returns the number of
+// runs deleted
+func deleteTrackedURLs(tx *gorm.DB, crawlIDs []uint) (int64, error) {
+	var trackedIDs []uint
+	if err := tx.Model(&models.CrawlResult{}).
+		Where("id IN ?", crawlIDs).
+		Distinct().
+		Pluck("tracked_url_id", &trackedIDs).Error; err != nil {
+		return 0, err
+	}
+
+	runIDs := tx.Unscoped().Model(&models.CrawlResult{}).
+		Select("id").
+		Where("id IN ? OR tracked_url_id IN ?", crawlIDs, trackedIDs)
+	if err := services.DeleteLinkEdges(tx, runIDs); err != nil {
+		return 0, err
+	}
+
+	result := tx.Where("id IN ? OR tracked_url_id IN ?", crawlIDs, trackedIDs).Delete(&models.CrawlResult{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	// Rules scoped to a deleted URL can never trigger again; alerts are kept
+	// as history since they carry their own URL and context
+	if err := tx.Where("tracked_url_id IN ?", trackedIDs).Delete(&models.AlertRule{}).Error; err != nil {
+		return 0, err
+	}
+
+	// Schedules would otherwise keep recrawling and recreate the URL
+	if err := tx.Where("tracked_url_id IN ?", trackedIDs).Delete(&models.Schedule{}).Error; err != nil {
+		return 0, err
+	}
+
+	if err := tx.Where("id IN ?", trackedIDs).Delete(&models.TrackedURL{}).Error; err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
+
+func toTrackedURLResponse(tracked models.TrackedURL, latest *models.CrawlResult) models.TrackedURLResponse {
+	response := models.TrackedURLResponse{
+		ID:            tracked.ID,
+		URL:           tracked.URL,
+		RunCount:      tracked.RunCount,
+		LastCrawledAt: tracked.LastCrawledAt,
+		CreatedAt:     tracked.CreatedAt,
+	}
+	if latest != nil {
+		summary := toCrawlListResponse(*latest)
+		response.LatestRun = &summary
+	}
+	return response
+}
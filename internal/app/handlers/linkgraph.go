@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetLinkGraph returns the link graph of a site with per-page degrees, click
+// depth and PageRank, plus orphan pages and pages without outlinks. It is
+// built from the latest completed crawl of each of the user's pages on the
+// host; ?start= sets the page click depth is measured from.
+func (h *CrawlHandler) GetLinkGraph(c *gin.Context) {
+	graph, ok := h.loadLinkGraph(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// ExportLinkGraph downloads the link graph of a site as GraphML, DOT or JSON
+// (?format=graphml|dot|json). External links are included with
+// ?external=true.
+func (h *CrawlHandler) ExportLinkGraph(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "graphml" && format != "dot" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be graphml, dot or json"})
+		return
+	}
+
+	graph, ok := h.loadLinkGraph(c)
+	if !ok {
+		return
+	}
+
+	nodes, edges := services.ExportLinkGraph(graph, c.Query("external") == "true")
+	filename := strings.NewReplacer(":", "_", "/", "_").Replace(graph.Host) + "-links." + format
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	switch format {
+	case "graphml":
+		c.Header("Content-Type", "application/graphml+xml")
+		c.Status(http.StatusOK)
+		services.WriteGraphML(c.Writer, nodes, edges)
+	case "dot":
+		c.Header("Content-Type", "text/vnd.graphviz")
+		c.Status(http.StatusOK)
+		services.WriteDOT(c.Writer, graph.Host, nodes, edges)
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"host":      graph.Host,
+			"start_url": graph.StartURL,
+			"nodes":     nodes,
+			"edges":     edges,
+		})
+	}
+}
+
+// loadLinkGraph builds the link graph of the :host URL parameter. On failure
+// the error response has already been written and false is returned.
+func (h *CrawlHandler) loadLinkGraph(c *gin.Context) (*models.LinkGraph, bool) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	host := strings.ToLower(strings.TrimSpace(c.Param("host")))
+	if host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid host"})
+		return nil, false
+	}
+
+	var latestRuns []models.CrawlResult
+	if err := h.db.DB.Select("id", "url").
+		Where("id IN (?)", services.LatestRunIDs(h.db.DB, userID)).
+		Find(&latestRuns).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl results"})
+		return nil, false
+	}
+
+	var pages []models.CrawlResult
+	var pageIDs []uint
+	for _, run := range latestRuns {
+		parsed, err := url.Parse(run.URL)
+		if err != nil || strings.ToLower(parsed.Hostname()) != host {
+			continue
+		}
+		pages = append(pages, run)
+		pageIDs = append(pageIDs, run.ID)
+	}
+	if len(pages) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No crawled pages for this host"})
+		return nil, false
+	}
+
+	var edges []models.LinkEdge
+	if err := h.db.DB.Where("crawl_id IN ?", pageIDs).Find(&edges).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch links"})
+		return nil, false
+	}
+
+	graph, err := services.BuildLinkGraph(host, pages, edges, c.Query("start"), h.urls)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return graph, true
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCrawlMarkup returns the markup conformance findings of a crawl
+func (h *CrawlHandler) GetCrawlMarkup(c *gin.Context) {
+	crawl, ok := h.getOwnedCrawl(c)
+	if !ok {
+		return
+	}
+
+	report := crawl.Markup
+	if report.Summary.ByType == nil {
+		report.Summary.ByType = make(map[string]int)
+	}
+	if report.Findings == nil {
+		report.Findings = make([]models.MarkupFinding, 0)
+	}
+
+	c.JSON(http.StatusOK, models.MarkupResponse{
+		CrawlID:  crawl.ID,
+		URL:      crawl.URL,
+		Summary:  report.Summary,
+		Findings: report.Findings,
+	})
+}
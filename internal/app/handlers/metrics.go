@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type MetricsHandler struct {
+	svc *services.Services
+}
+
+func NewMetricsHandler(svc *services.Services) *MetricsHandler {
+	return &MetricsHandler{svc: svc}
+}
+
+// GetMetrics reports the crawl backlog and the queue depth of every host
+// the user has outbound requests in flight to
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetricsResponse{
+		CrawlQueueDepth: h.svc.Queue.Depth(),
+		Hosts:           h.svc.Hosts.Stats(userID.(uint64)),
+	})
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCrawlOutline returns the heading tree and outline validation issues of a crawl
+func (h *CrawlHandler) GetCrawlOutline(c *gin.Context) {
+	crawl, ok := h.getOwnedCrawl(c)
+	if !ok {
+		return
+	}
+
+	outline := crawl.Outline.Headings
+	if outline == nil {
+		outline = make([]*models.HeadingNode, 0)
+	}
+	issues := crawl.Outline.Issues
+	if issues == nil {
+		issues = make([]models.OutlineIssue, 0)
+	}
+
+	c.JSON(http.StatusOK, models.OutlineResponse{
+		CrawlID:  crawl.ID,
+		URL:      crawl.URL,
+		Headings: crawl.Headings,
+		Outline:  outline,
+		Issues:   issues,
+	})
+}
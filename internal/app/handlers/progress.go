@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// progressKeepAlive is how often a comment is sent on an idle stream so
+	// proxies do not close it
+	progressKeepAlive = 15 * time.Second
+	// progressPollInterval is how often the database is checked for crawls
+	// that are not running in this process
+	progressPollInterval = 2 * time.Second
+	// progressWriteTimeout bounds each write to an event stream, replacing
+	// the server's WriteTimeout for the lifetime of the stream
+	progressWriteTimeout = 30 * time.Second
+)
+
+// StreamCrawlEvents streams the progress of a crawl as Server-Sent Events.
+// Clients resume a dropped stream by sending the last event ID they received
+// in the Last-Event-ID header (or the last_event_id query parameter). The
+// stream ends with a completed or failed event.
+func (h *CrawlHandler) StreamCrawlEvents(c *gin.Context) {
+	crawl, ok := h.getOwnedCrawl(c)
+	if !ok {
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	afterID := 0
+	if lastEventID != "" {
+		id, err := strconv.Atoi(lastEventID)
+		if err != nil || id < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+		afterID = id
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	stream := newEventStream(c.Writer)
+	keepAlive := time.NewTicker(progressKeepAlive)
+	defer keepAlive.Stop()
+	var poll <-chan time.Time
+	ctx := c.Request.Context()
+
+	for {
+		events, done, changed, found := h.progress.Events(crawl.ID, afterID)
+		if !found {
+			// The crawl has no progress stream in this process, e.g. because
+			// it finished a while ago or runs on another instance. Poll its
+			// status until it finishes and only send the final event; the
+			// stream is picked up if it appears in the meantime.
+			if final := h.storedStatusEvent(crawl.ID); final != nil {
+				if stream.event(*final) {
+					stream.flush()
+				}
+				return
+			}
+			if poll == nil {
+				ticker := time.NewTicker(progressPollInterval)
+				defer ticker.Stop()
+				poll = ticker.C
+			}
+		}
+
+		for _, event := range events {
+			if !stream.event(event) {
+				return
+			}
+			afterID = event.ID
+		}
+		if !stream.flush() || done {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		case <-poll:
+		case <-keepAlive.C:
+			if !stream.comment("keep-alive") || !stream.flush() {
+				return
+			}
+		}
+	}
+}
+
+// storedStatusEvent returns the final event of a crawl reconstructed from the
+// database, or nil while the crawl is still running
+func (h *CrawlHandler) storedStatusEvent(crawlID uint) *models.ProgressEvent {
+	var crawl models.CrawlResult
+	if err := h.db.DB.First(&crawl, crawlID).Error; err != nil {
+		return &models.ProgressEvent{
+			CrawlID: crawlID,
+			Type:    models.ProgressFailed,
+			Time:    time.Now(),
+			Data:    models.CrawlFailedEventData{Error: "crawl no longer exists"},
+		}
+	}
+
+	switch crawl.Status {
+	case models.CrawlStatusRunning:
+		return nil
+	case models.CrawlStatusFailed:
+		return &models.ProgressEvent{
+			CrawlID: crawl.ID,
+			Type:    models.ProgressFailed,
+			Time:    crawl.UpdatedAt,
+			Data:    models.CrawlFailedEventData{URL: crawl.URL, Error: crawl.Error},
+		}
+	}
+	return &models.ProgressEvent{
+		CrawlID: crawl.ID,
+		Type:    models.ProgressCompleted,
+		Time:    crawl.UpdatedAt,
+		Data: models.CrawlEventData{
+			CrawlID:           crawl.ID,
+			TrackedURLID:      crawl.TrackedURLID,
+			URL:               crawl.URL,
+			PageTitle:         crawl.PageTitle,
+			HTMLVersion:       crawl.HTMLVersion,
+			InternalLinks:     crawl.InternalLinks,
+			ExternalLinks:     crawl.ExternalLinks,
+			InaccessibleLinks: len(crawl.InaccessibleLinks),
+			HasLoginForm:      crawl.HasLoginForm,
+		},
+	}
+}
+
+// eventStream writes Server-Sent Events. The write deadline is pushed back
+// before every write, so the server's WriteTimeout does not cut off long
+// streams, and a failed write ends the stream.
+type eventStream struct {
+	w  gin.ResponseWriter
+	rc *http.ResponseController
+}
+
+func newEventStream(w gin.ResponseWriter) *eventStream {
+	// Flush through the underlying writer, as gin's Flush drops the error
+	w.WriteHeaderNow()
+	var underlying http.ResponseWriter = w
+	if u, ok := w.(interface{ Unwrap() http.ResponseWriter }); ok {
+		underlying = u.Unwrap()
+	}
+	return &eventStream{w: w, rc: http.NewResponseController(underlying)}
+}
+
+// event writes a single event. Events without an ID (those reconstructed
+// from the database) are sent without one so they do not move the client's
+// Last-Event-ID.
+func (s *eventStream) event(event models.ProgressEvent) bool {
+	message := sse.Event{Event: event.Type, Data: event}
+	if event.ID > 0 {
+		message.Id = strconv.Itoa(event.ID)
+	}
+	s.extendDeadline()
+	return sse.Encode(s.w, message) == nil
+}
+
+// comment writes an SSE comment, which clients ignore
+func (s *eventStream) comment(text string) bool {
+	s.extendDeadline()
+	_, err := s.w.WriteString(": " + text + "\n\n")
+	return err == nil
+}
+
+func (s *eventStream) flush() bool {
+	s.extendDeadline()
+	return s.rc.Flush() == nil
+}
+
+// extendDeadline allows the next write progressWriteTimeout. Writers that do
+// not support deadlines, such as test recorders, are left alone.
+func (s *eventStream) extendDeadline() {
+	s.rc.SetWriteDeadline(time.Now().Add(progressWriteTimeout))
+}
@@ -5,13 +5,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/ayeshakhan-29/test-task-BE/internal/config"
 	"github.com/ayeshakhan-29/test-task-BE/internal/database"
 	"github.com/ayeshakhan-29/test-task-BE/internal/middleware"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.Engine, db *database.Database) {
+func SetupRoutes(router *gin.Engine, db *database.Database, cfg *config.Config, svc *services.Services) {
 	// Configure CORS middleware
 	// Get allowed origins from environment variable
 	allowedOrigins := os.Getenv("ALLOWED_ORIGINS")
@@ -54,10 +56,73 @@ func SetupRoutes(router *gin.Engine, db *database.Database) {
 		// Protected routes
 		protected := v1.Group("", middleware.AuthMiddleware())
 		{
-			crawlHandler := NewCrawlHandler(db)
+			crawlHandler := NewCrawlHandler(db, cfg, svc)
 			protected.POST("/crawl", crawlHandler.CrawlURL)
+			protected.POST("/analyze/html", crawlHandler.AnalyzeHTML)
 			protected.GET("/analyzed-url/:id", crawlHandler.GetCrawlByID)
 			protected.GET("/crawls", crawlHandler.ListCrawls)
+			protected.GET("/crawls/duplicates", crawlHandler.GetDuplicateClusters)
+			protected.GET("/crawls/diff", crawlHandler.GetCrawlDiff)
+			protected.GET("/crawls/:id/cookies", crawlHandler.GetCrawlCookies)
+			protected.GET("/crawls/:id/events", crawlHandler.StreamCrawlEvents)
+			protected.GET("/crawls/:id/forms", crawlHandler.GetCrawlForms)
+			protected.GET("/crawls/:id/markup", crawlHandler.GetCrawlMarkup)
+			protected.GET("/crawls/:id/outline", crawlHandler.GetCrawlOutline)
+			protected.GET("/crawls/:id/score", crawlHandler.GetCrawlScore)
+			protected.GET("/crawls/:id/similar", crawlHandler.GetSimilarCrawls)
+			protected.GET("/crawls/:id/snapshot", crawlHandler.GetCrawlSnapshot)
+			protected.GET("/crawls/:id/third-parties", crawlHandler.GetCrawlThirdParties)
+			protected.GET("/storage/usage", crawlHandler.GetStorageUsage)
+			protected.GET("/sites/:host/graph", crawlHandler.GetLinkGraph)
+			protected.GET("/sites/:host/graph/export", crawlHandler.ExportLinkGraph)
+			protected.GET("/urls", crawlHandler.ListTrackedURLs)
+			protected.GET("/urls/:id/runs", crawlHandler.GetRunHistory)
+			protected.PATCH("/urls/:id/runs/:run_id", crawlHandler.UpdateRun)
+			protected.DELETE("/urls/:id/runs/:run_id", crawlHandler.DeleteRun)
+
+			metricsHandler := NewMetricsHandler(svc)
+			protected.GET("/metrics", metricsHandler.GetMetrics)
+
+			batchHandler := NewBatchHandler(db, svc)
+			protected.POST("/crawls/batch", batchHandler.SubmitBatch)
+			protected.GET("/crawls/batches", batchHandler.ListBatches)
+			protected.GET("/crawls/batch/:id", batchHandler.GetBatch)
+			protected.GET("/crawls/batch/:id/results", batchHandler.GetBatchResults)
+			protected.GET("/crawls/batch/:id/report", batchHandler.GetBatchReport)
+
+			scheduleHandler := NewScheduleHandler(db, svc)
+			protected.POST("/schedules", scheduleHandler.CreateSchedule)
+			protected.GET("/schedules", scheduleHandler.ListSchedules)
+			protected.GET("/schedules/:id", scheduleHandler.GetSchedule)
+			protected.PATCH("/schedules/:id", scheduleHandler.UpdateSchedule)
+			protected.DELETE("/schedules/:id", scheduleHandler.DeleteSchedule)
+
+			alertHandler := NewAlertHandler(db)
+			protected.POST("/alert-rules", alertHandler.CreateAlertRule)
+			protected.GET("/alert-rules", alertHandler.ListAlertRules)
+			protected.PATCH("/alert-rules/:id", alertHandler.UpdateAlertRule)
+			protected.DELETE("/alert-rules/:id", alertHandler.DeleteAlertRule)
+			protected.GET("/alerts", alertHandler.ListAlerts)
+			protected.POST("/alerts/:id/acknowledge", alertHandler.AcknowledgeAlert)
+			protected.POST("/alerts/:id/resolve", alertHandler.ResolveAlert)
+
+			scoringHandler := NewScoringHandler(db, svc)
+			protected.POST("/scoring-profiles", scoringHandler.CreateScoringProfile)
+			protected.GET("/scoring-profiles", scoringHandler.ListScoringProfiles)
+			protected.GET("/scoring-profiles/defaults", scoringHandler.GetDefaultScoringRules)
+			protected.GET("/scoring-profiles/:id", scoringHandler.GetScoringProfile)
+			protected.PATCH("/scoring-profiles/:id", scoringHandler.UpdateScoringProfile)
+			protected.DELETE("/scoring-profiles/:id", scoringHandler.DeleteScoringProfile)
+
+			webhookHandler := NewWebhookHandler(db, svc)
+			protected.POST("/webhooks", webhookHandler.CreateWebhook)
+			protected.GET("/webhooks", webhookHandler.ListWebhooks)
+			protected.GET("/webhooks/:id", webhookHandler.GetWebhook)
+			protected.PATCH("/webhooks/:id", webhookHandler.UpdateWebhook)
+			protected.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+			protected.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+			protected.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+
 			protected.DELETE("/delete/:id", crawlHandler.DeleteCrawl)
 			protected.DELETE("/bulk-delete", crawlHandler.BulkDeleteCrawls)
 		}
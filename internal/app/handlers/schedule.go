@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/ayeshakhan-29/test-task-BE/internal/database"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ScheduleHandler struct {
+	db   *database.Database
+	urls *services.URLCanonicalizer
+}
+
+func NewScheduleHandler(db *database.Database, svc *services.Services) *ScheduleHandler {
+	return &ScheduleHandler{db: db, urls: svc.URLs}
+}
+
+// CreateSchedule attaches a recurring recrawl to a URL
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	canonicalURL, err := h.urls.Canonicalize(req.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL"})
+		return
+	}
+
+	schedule := models.Schedule{
+		UserID:          userID.(uint64),
+		URL:             canonicalURL,
+		CronExpression:  strings.TrimSpace(req.CronExpression),
+		IntervalSeconds: req.IntervalSeconds,
+		Timezone:        strings.TrimSpace(req.Timezone),
+		Enabled:         true,
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+
+	if err := prepareSchedule(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.db.DB.Transaction(func(tx *gorm.DB) error {
+		tracked, err := services.FindOrCreateTrackedURL(tx, schedule.UserID, schedule.URL)
+		if err != nil {
+			return err
+		}
+		schedule.TrackedURLID = tracked.ID
+		return tx.Create(&schedule).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// ListSchedules lists the user's schedules
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	schedules := make([]models.Schedule, 0)
+	if err := h.db.DB.Where("user_id = ?", userID).Order("id").Find(&schedules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch schedules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// GetSchedule returns a single schedule
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	schedule, ok := h.getOwnedSchedule(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateSchedule changes the timing of a schedule or enables/disables it
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	schedule, ok := h.getOwnedSchedule(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	// Setting one kind of timing replaces the other
+	if req.CronExpression != nil {
+		schedule.CronExpression = strings.TrimSpace(*req.CronExpression)
+		if schedule.CronExpression != "" && req.IntervalSeconds == nil {
+			schedule.IntervalSeconds = 0
+		}
+	}
+	if req.IntervalSeconds != nil {
+		schedule.IntervalSeconds = *req.IntervalSeconds
+		if schedule.IntervalSeconds > 0 && req.CronExpression == nil {
+			schedule.CronExpression = ""
+		}
+	}
+	if req.Timezone != nil {
+		schedule.Timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+
+	// The next run is recomputed from now, so a changed timing takes effect
+	// immediately and a re-enabled schedule does not fire for missed runs
+	schedule.NextRunAt = nil
+	if err := prepareSchedule(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.DB.Model(schedule).Select(
+		"cron_expression", "interval_seconds", "timezone", "enabled", "next_run_at",
+	).Updates(schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule removes a schedule
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	schedule, ok := h.getOwnedSchedule(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DB.Delete(schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+}
+
+// prepareSchedule validates the timing of a schedule and computes its next run
+func prepareSchedule(schedule *models.Schedule) error {
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %s", schedule.Timezone)
+	}
+
+	hasCron := schedule.CronExpression != ""
+	hasInterval := schedule.IntervalSeconds != 0
+	if hasCron == hasInterval {
+		return fmt.Errorf("exactly one of cron_expression or interval_seconds is required")
+	}
+
+	next, err := services.NextScheduleRun(schedule, time.Now())
+	if err != nil {
+		return err
+	}
+	schedule.NextRunAt = &next
+	return nil
+}
+
+// getOwnedSchedule loads the schedule referenced by the :id URL parameter and
+// verifies that it belongs to the authenticated user. On failure the error
+// response has already been written and false is returned.
+func (h *ScheduleHandler) getOwnedSchedule(c *gin.Context) (*models.Schedule, bool) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	scheduleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID format"})
+		return nil, false
+	}
+
+	var schedule models.Schedule
+	if err := h.db.DB.First(&schedule, "id = ?", scheduleID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return nil, false
+	}
+
+	if schedule.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this schedule"})
+		return nil, false
+	}
+
+	return &schedule, true
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetCrawlScore returns the health score breakdown of a crawl. With a
+// profile_id query parameter the crawl is scored with that profile instead
+// of returning the stored score.
+func (h *CrawlHandler) GetCrawlScore(c *gin.Context) {
+	crawl, ok := h.getOwnedCrawl(c)
+	if !ok {
+		return
+	}
+
+	if crawl.HealthScore == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Crawl has not been scored"})
+		return
+	}
+
+	breakdown := crawl.ScoreBreakdown
+	if v := c.Query("profile_id"); v != "" {
+		profileID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile_id value: " + v})
+			return
+		}
+
+		var profile models.ScoringProfile
+		if err := h.db.DB.Where("id = ? AND user_id = ?", profileID, crawl.UserID).First(&profile).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Scoring profile not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+			return
+		}
+		breakdown = services.ComputeScore(crawl, &profile)
+	}
+	if breakdown.Items == nil {
+		breakdown.Items = make([]models.ScoreItem, 0)
+	}
+
+	c.JSON(http.StatusOK, models.ScoreResponse{
+		CrawlID:        crawl.ID,
+		URL:            crawl.URL,
+		ScoreBreakdown: breakdown,
+	})
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/ayeshakhan-29/test-task-BE/internal/database"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ScoringHandler struct {
+	db       *database.Database
+	rescorer *services.Rescorer
+}
+
+func NewScoringHandler(db *database.Database, svc *services.Services) *ScoringHandler {
+	return &ScoringHandler{db: db, rescorer: svc.Rescorer}
+}
+
+// CreateScoringProfile adds a scoring profile. A default profile replaces
+// the previous default and rescores the user's crawls in the background.
+func (h *ScoringHandler) CreateScoringProfile(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateScoringProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	profile := models.ScoringProfile{
+		UserID: userID.(uint64),
+		Name:   strings.TrimSpace(req.Name),
+		Rules:  req.Rules,
+	}
+	if profile.Rules == nil {
+		profile.Rules = make(models.ScoringRules)
+	}
+	if req.IsDefault != nil {
+		profile.IsDefault = *req.IsDefault
+	}
+	if profile.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	if err := services.ValidateScoringRules(profile.Rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.saveProfile(&profile, true); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create scoring profile"})
+		return
+	}
+
+	response := models.ScoringProfileResponse{ScoringProfile: profile}
+	if profile.IsDefault {
+		h.rescorer.Rescore(profile.UserID)
+		response.Rescoring = true
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListScoringProfiles lists the user's scoring profiles
+func (h *ScoringHandler) ListScoringProfiles(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	profiles := make([]models.ScoringProfile, 0)
+	if err := h.db.DB.Where("user_id = ?", userID).Order("id").Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scoring profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profiles)
+}
+
+// GetDefaultScoringRules returns the built-in rules used when the user has
+// no default profile and for rules a profile does not list
+func (h *ScoringHandler) GetDefaultScoringRules(c *gin.Context) {
+	c.JSON(http.StatusOK, services.DefaultScoringRules)
+}
+
+// GetScoringProfile returns a single scoring profile
+func (h *ScoringHandler) GetScoringProfile(c *gin.Context) {
+	profile, ok := h.getOwnedProfile(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateScoringProfile renames a profile, replaces its rules or makes it the
+// default. Changes to the default profile rescore the user's crawls in the
+// background.
+func (h *ScoringHandler) UpdateScoringProfile(c *gin.Context) {
+	profile, ok := h.getOwnedProfile(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateScoringProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	wasDefault := profile.IsDefault
+	if req.Name != nil {
+		profile.Name = strings.TrimSpace(*req.Name)
+		if profile.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name must not be empty"})
+			return
+		}
+	}
+	if req.Rules != nil {
+		if err := services.ValidateScoringRules(req.Rules); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		profile.Rules = req.Rules
+	}
+	if req.IsDefault != nil {
+		profile.IsDefault = *req.IsDefault
+	}
+
+	if err := h.saveProfile(profile, false); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update scoring profile"})
+		return
+	}
+
+	response := models.ScoringProfileResponse{ScoringProfile: *profile}
+	if wasDefault || profile.IsDefault {
+		h.rescorer.Rescore(profile.UserID)
+		response.Rescoring = true
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteScoringProfile removes a profile. Deleting the default profile
+// rescores the user's crawls with the built-in rules in the background.
+func (h *ScoringHandler) DeleteScoringProfile(c *gin.Context) {
+	profile, ok := h.getOwnedProfile(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DB.Delete(profile).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete scoring profile"})
+		return
+	}
+
+	response := gin.H{"message": "Scoring profile deleted successfully"}
+	if profile.IsDefault {
+		h.rescorer.Rescore(profile.UserID)
+		response["rescoring"] = true
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// saveProfile creates or updates a profile. A default profile clears the
+// default flag of the user's other profiles in the same transaction.
+func (h *ScoringHandler) saveProfile(profile *models.ScoringProfile, create bool) error {
+	return h.db.DB.Transaction(func(tx *gorm.DB) error {
+		if profile.IsDefault {
+			if err := tx.Model(&models.ScoringProfile{}).
+				Where("user_id = ? AND is_default = ? AND id <> ?", profile.UserID, true, profile.ID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		if create {
+			return tx.Create(profile).Error
+		}
+		return tx.Model(profile).Select("name", "rules", "is_default").Updates(profile).Error
+	})
+}
+
+// getOwnedProfile loads the scoring profile referenced by the :id URL
+// parameter and verifies that it belongs to the authenticated user. On
+// failure the error response has already been written and false is
+// returned.
+func (h *ScoringHandler) getOwnedProfile(c *gin.Context) (*models.ScoringProfile, bool) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	profileID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scoring profile ID format"})
+		return nil, false
+	}
+
+	var profile models.ScoringProfile
+	if err := h.db.DB.First(&profile, "id = ?", profileID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Scoring profile not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return nil, false
+	}
+
+	if profile.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this scoring profile"})
+		return nil, false
+	}
+
+	return &profile, true
+}
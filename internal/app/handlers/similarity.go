@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSimilarCrawls lists the user's other crawls whose content fingerprint is
+// at least as similar as the requested threshold
+func (h *CrawlHandler) GetSimilarCrawls(c *gin.Context) {
+	crawl, ok := h.getOwnedCrawl(c)
+	if !ok {
+		return
+	}
+
+	threshold, err := h.similarityThreshold(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := models.SimilarCrawlsResponse{
+		CrawlID:   crawl.ID,
+		URL:       crawl.URL,
+		Threshold: threshold,
+		Similar:   make([]models.SimilarCrawl, 0),
+	}
+
+	target, err := services.ParseFingerprint(crawl.ContentFingerprint)
+	if err != nil {
+		// Crawls without text content have nothing to compare against
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Compare against the latest run of every other URL
+	var candidates []models.CrawlResult
+	if err := h.db.DB.Select("id", "url", "page_title", "content_fingerprint").
+		Where("user_id = ? AND tracked_url_id <> ? AND content_fingerprint <> ''", crawl.UserID, crawl.TrackedURLID).
+		Where("id IN (?)", services.LatestRunIDs(h.db.DB, crawl.UserID)).
+		Find(&candidates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl results"})
+		return
+	}
+
+	for _, candidate := range candidates {
+		hash, err := services.ParseFingerprint(candidate.ContentFingerprint)
+		if err != nil {
+			continue
+		}
+		similarity := services.Similarity(target, hash)
+		if similarity < threshold {
+			continue
+		}
+		response.Similar = append(response.Similar, models.SimilarCrawl{
+			ID:         candidate.ID,
+			URL:        candidate.URL,
+			PageTitle:  candidate.PageTitle,
+			Similarity: similarity,
+		})
+	}
+
+	sort.SliceStable(response.Similar, func(i, j int) bool {
+		return response.Similar[i].Similarity > response.Similar[j].Similarity
+	})
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDuplicateClusters groups the user's crawls of a site into clusters of
+// near-duplicate pages. Without a host parameter all crawls are considered.
+func (h *CrawlHandler) GetDuplicateClusters(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	threshold, err := h.similarityThreshold(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	host := strings.ToLower(strings.TrimSpace(c.Query("host")))
+
+	var crawls []models.CrawlResult
+	if err := h.db.DB.Select("id", "url", "page_title", "content_fingerprint").
+		Where("user_id = ? AND content_fingerprint <> ''", userID).
+		Where("id IN (?)", services.LatestRunIDs(h.db.DB, userID)).
+		Order("id").
+		Find(&crawls).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl results"})
+		return
+	}
+
+	var pages []models.CrawlResult
+	var hashes []uint64
+	for _, crawl := range crawls {
+		if host != "" {
+			parsed, err := url.Parse(crawl.URL)
+			if err != nil || strings.ToLower(parsed.Hostname()) != host {
+				continue
+			}
+		}
+		hash, err := services.ParseFingerprint(crawl.ContentFingerprint)
+		if err != nil {
+			continue
+		}
+		pages = append(pages, crawl)
+		hashes = append(hashes, hash)
+	}
+
+	response := models.DuplicateReportResponse{
+		Host:          host,
+		Threshold:     threshold,
+		PagesAnalyzed: len(pages),
+		Clusters:      make([]models.DuplicateCluster, 0),
+	}
+
+	for _, members := range services.ClusterFingerprints(hashes, threshold) {
+		cluster := models.DuplicateCluster{MinSimilarity: 1}
+		for i, a := range members {
+			page := pages[a]
+			cluster.Pages = append(cluster.Pages, models.DuplicatePage{
+				ID:        page.ID,
+				URL:       page.URL,
+				PageTitle: page.PageTitle,
+			})
+			for _, b := range members[i+1:] {
+				if s := services.Similarity(hashes[a], hashes[b]); s < cluster.MinSimilarity {
+					cluster.MinSimilarity = s
+				}
+			}
+		}
+		response.Clusters = append(response.Clusters, cluster)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// similarityThreshold reads the optional threshold query parameter, falling
+// back to the configured default
+func (h *CrawlHandler) similarityThreshold(c *gin.Context) (float64, error) {
+	v := c.Query("threshold")
+	if v == "" {
+		return h.cfg.Analysis.SimilarityThreshold, nil
+	}
+	threshold, err := strconv.ParseFloat(v, 64)
+	if err != nil || threshold < 0 || threshold > 1 {
+		return 0, fmt.Errorf("threshold must be a number between 0 and 1")
+	}
+	return threshold, nil
+}
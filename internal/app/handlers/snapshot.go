@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCrawlSnapshot downloads the stored HTML of a crawl
+func (h *CrawlHandler) GetCrawlSnapshot(c *gin.Context) {
+	crawl, ok := h.getOwnedCrawl(c)
+	if !ok {
+		return
+	}
+
+	if crawl.SnapshotHash == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No snapshot stored for this crawl"})
+		return
+	}
+
+	reader, err := h.snapshots.Open(c.Request.Context(), crawl.SnapshotHash)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Snapshot not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read snapshot"})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "text/html; charset=utf-8", reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="crawl-%d.html"`, crawl.ID),
+		"ETag":                `"` + crawl.SnapshotHash + `"`,
+	})
+}
+
+// GetStorageUsage reports how much of the snapshot quota the user has used
+func (h *CrawlHandler) GetStorageUsage(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	used, count, err := h.snapshots.Usage(c.Request.Context(), userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute storage usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.StorageUsageResponse{
+		UsedBytes:  used,
+		QuotaBytes: h.snapshots.Quota(),
+		Snapshots:  count,
+	})
+}
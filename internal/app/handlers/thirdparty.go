@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCrawlThirdParties returns the third-party inventory and Subresource
+// Integrity issues of a crawl
+func (h *CrawlHandler) GetCrawlThirdParties(c *gin.Context) {
+	crawl, ok := h.getOwnedCrawl(c)
+	if !ok {
+		return
+	}
+
+	report := crawl.ThirdParties
+	if report.Summary.ByCategory == nil {
+		report.Summary.ByCategory = make(map[string]int)
+	}
+	if report.Domains == nil {
+		report.Domains = make([]models.ThirdPartyDomain, 0)
+	}
+	if report.Resources == nil {
+		report.Resources = make([]models.ThirdPartyResource, 0)
+	}
+	if report.SRIIssues == nil {
+		report.SRIIssues = make([]models.SRIIssue, 0)
+	}
+
+	c.JSON(http.StatusOK, models.ThirdPartyResponse{
+		CrawlID:          crawl.ID,
+		URL:              crawl.URL,
+		ThirdPartyReport: report,
+	})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxHTMLUploadSize limits the size of uploaded HTML
+	maxHTMLUploadSize = 10 << 20
+	// defaultUploadName names pasted HTML that comes without a file name
+	defaultUploadName = "document.html"
+)
+
+// AnalyzeHTML runs the crawl analysis on uploaded HTML without fetching it.
+// The HTML is the raw request body or a multipart upload in the "file"
+// field. Relative links resolve against the optional base_url form field
+// or query parameter. The analysis runs on the crawl queue like CrawlURL,
+// as checking the links can take longer than the server's write timeout.
+func (h *CrawlHandler) AnalyzeHTML(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxHTMLUploadSize)
+	body, name, err := readUploadedHTML(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No HTML uploaded"})
+		return
+	}
+
+	baseURL := strings.TrimSpace(c.PostForm("base_url"))
+	if baseURL == "" {
+		baseURL = strings.TrimSpace(c.Query("base_url"))
+	}
+
+	run, err := h.crawler.BeginUpload(c.Request.Context(), userID.(uint64), body, baseURL, name)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidURL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid base URL"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze HTML: " + err.Error()})
+		return
+	}
+
+	done := make(chan crawlOutcome, 1)
+	job := services.CrawlJob{UserID: run.UserID, URL: run.URL, Run: run, Upload: body}
+	response, ok := h.enqueueRun(c, job, done)
+	if !ok {
+		return
+	}
+	result, ok := h.awaitCrawl(c, response, done)
+	if !ok {
+		return
+	}
+	output, err := result.output, result.err
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze HTML: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, output.Result)
+}
+
+// readUploadedHTML returns the uploaded HTML and its file name
+func readUploadedHTML(c *gin.Context) ([]byte, string, error) {
+	if c.ContentType() != "multipart/form-data" {
+		body, err := io.ReadAll(c.Request.Body)
+		return body, defaultUploadName, err
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", errors.New("missing HTML file in the file field")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	body, err := io.ReadAll(file)
+	if err != nil {
+		return nil, "", err
+	}
+	name := path.Base(strings.ReplaceAll(header.Filename, `\`, "/"))
+	if name == "." || name == "/" {
+		name = defaultUploadName
+	}
+	return body, name, nil
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/services"
+	"github.com/ayeshakhan-29/test-task-BE/internal/database"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// deliveryListLimit caps the number of deliveries returned per request
+const deliveryListLimit = 100
+
+type WebhookHandler struct {
+	db       *database.Database
+	webhooks *services.WebhookDispatcher
+}
+
+func NewWebhookHandler(db *database.Database, svc *services.Services) *WebhookHandler {
+	return &WebhookHandler{db: db, webhooks: svc.Webhooks}
+}
+
+// CreateWebhook registers an endpoint for the given events. The signing
+// secret is returned only in this response.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if err := validateWebhookEvents(req.Events); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = services.GenerateWebhookSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+			return
+		}
+	}
+
+	webhook := models.Webhook{
+		UserID: userID.(uint64),
+		URL:    req.URL,
+		Secret: secret,
+		Events: req.Events,
+		Active: true,
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := h.db.DB.Create(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreateWebhookResponse{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// ListWebhooks lists the user's webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	webhooks := make([]models.Webhook, 0)
+	if err := h.db.DB.Where("user_id = ?", userID).Order("id").Find(&webhooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// GetWebhook returns a single webhook
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhook, ok := h.getOwnedWebhook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook changes the URL or events of a webhook or enables/disables it
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := h.getOwnedWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		if err := validateWebhookEvents(req.Events); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		webhook.Events = req.Events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := h.db.DB.Model(webhook).Select("url", "events", "active").Updates(webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook removes a webhook together with its delivery log
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := h.getOwnedWebhook(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// ListDeliveries returns the most recent deliveries of a webhook, optionally
+// filtered by status
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhook, ok := h.getOwnedWebhook(c)
+	if !ok {
+		return
+	}
+
+	query := h.db.DB.Where("webhook_id = ?", webhook.ID)
+	if status := c.Query("status"); status != "" {
+		if status != models.DeliveryStatusPending && status != models.DeliveryStatusSucceeded && status != models.DeliveryStatusDead {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, succeeded or dead"})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	if err := query.Order("id DESC").Limit(deliveryListLimit).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver sends a delivery again, e.g. after it was dead-lettered
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	webhook, ok := h.getOwnedWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID format"})
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := h.db.DB.Where("id = ? AND webhook_id = ?", deliveryID, webhook.ID).First(&delivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return
+	}
+
+	if err := h.webhooks.Redeliver(&delivery); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue redelivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func validateWebhookEvents(events []string) error {
+	if len(events) == 0 {
+		return fmt.Errorf("at least one event is required")
+	}
+	for _, event := range events {
+		if !services.WebhookEvents[event] {
+			return fmt.Errorf("unsupported webhook event: %s", event)
+		}
+	}
+	return nil
+}
+
+// getOwnedWebhook loads the webhook referenced by the :id URL parameter and
+// verifies that it belongs to the authenticated user. On failure the error
+// response has already been written and false is returned.
+func (h *WebhookHandler) getOwnedWebhook(c *gin.Context) (*models.Webhook, bool) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID format"})
+		return nil, false
+	}
+
+	var webhook models.Webhook
+	if err := h.db.DB.First(&webhook, "id = ?", webhookID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return nil, false
+	}
+
+	if webhook.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this webhook"})
+		return nil, false
+	}
+
+	return &webhook, true
+}
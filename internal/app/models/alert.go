@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Alert rule types
+const (
+	AlertRuleNewInaccessibleLink = "new_inaccessible_link"
+	AlertRuleTitleChanged        = "title_changed"
+	AlertRuleLoginFormChanged    = "login_form_changed"
+	AlertRuleInternalLinksDrop   = "internal_links_drop"
+)
+
+// Alert status values
+const (
+	AlertStatusOpen         = "open"
+	AlertStatusAcknowledged = "acknowledged"
+	AlertStatusResolved     = "resolved"
+)
+
+// AlertRule is evaluated after every crawl against the previous run of the
+// same URL. Rules without a tracked URL apply to all of the user's URLs.
+// Threshold is only used by internal_links_drop, as a percentage.
+type AlertRule struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint64    `json:"user_id" gorm:"index;not null"`
+	TrackedURLID *uint     `json:"tracked_url_id" gorm:"index"`
+	Type         string    `json:"type" gorm:"size:50;not null"`
+	Threshold    float64   `json:"threshold" gorm:"default:0"`
+	Enabled      bool      `json:"enabled" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	User         User      `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// Alert is a triggered alert rule together with the crawl that triggered it
+type Alert struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint64     `json:"user_id" gorm:"index;not null"`
+	RuleID          uint       `json:"rule_id" gorm:"index"`
+	TrackedURLID    uint       `json:"tracked_url_id" gorm:"index"`
+	CrawlID         uint       `json:"crawl_id"`
+	PreviousCrawlID uint       `json:"previous_crawl_id"`
+	URL             string     `json:"url" gorm:"type:varchar(2000)"`
+	Type            string     `json:"type" gorm:"size:50;not null"`
+	Message         string     `json:"message" gorm:"type:text"`
+	Context         JSONMap    `json:"context" gorm:"type:JSON"`
+	Status          string     `json:"status" gorm:"size:20;index;not null"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at"`
+	ResolvedAt      *time.Time `json:"resolved_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	User            User       `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type CreateAlertRuleRequest struct {
+	Type         string   `json:"type" binding:"required"`
+	TrackedURLID *uint    `json:"tracked_url_id"`
+	Threshold    *float64 `json:"threshold"`
+	Enabled      *bool    `json:"enabled"`
+}
+
+type UpdateAlertRuleRequest struct {
+	Threshold *float64 `json:"threshold"`
+	Enabled   *bool    `json:"enabled"`
+}
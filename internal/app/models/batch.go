@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Batch status values
+const (
+	BatchStatusRunning   = "running"
+	BatchStatusCompleted = "completed"
+)
+
+// Batch item status values
+const (
+	BatchItemPending   = "pending"
+	BatchItemRunning   = "running"
+	BatchItemCompleted = "completed"
+	BatchItemFailed    = "failed"
+)
+
+// Batch is a bulk crawl submission. Completed and Failed count the finished
+// items; the batch completes once every item has finished.
+type Batch struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint64     `json:"user_id" gorm:"index;not null"`
+	Status     string     `json:"status" gorm:"size:20;not null;index"`
+	Total      int        `json:"total"`
+	Completed  int        `json:"completed"`
+	Failed     int        `json:"failed"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	User       User       `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// BatchItem is one URL of a batch and the crawl run it produced
+type BatchItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BatchID   uint      `json:"batch_id" gorm:"index;not null"`
+	URL       string    `json:"url" gorm:"type:varchar(2000);not null"`
+	Status    string    `json:"status" gorm:"size:20;not null"`
+	CrawlID   *uint     `json:"crawl_id"`
+	Error     string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Batch     Batch     `json:"-" gorm:"foreignKey:BatchID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// RejectedURL is an entry of a batch submission that was not crawled
+type RejectedURL struct {
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// BatchSubmitResponse is the created batch together with the entries that
+// were dropped while validating the submission
+type BatchSubmitResponse struct {
+	Batch      Batch         `json:"batch"`
+	Rejected   []RejectedURL `json:"rejected"`
+	Duplicates int           `json:"duplicates"`
+}
+
+// BatchProgressResponse describes how far a batch has got
+type BatchProgressResponse struct {
+	Batch
+	Pending  int     `json:"pending"`
+	Running  int     `json:"running"`
+	Progress float64 `json:"progress"`
+}
+
+// BatchItemResult is a batch item with the summary of its crawl
+type BatchItemResult struct {
+	BatchItem
+	Result *CrawlListResponse `json:"result,omitempty"`
+}
+
+// BatchResultsResponse lists the items of a batch
+type BatchResultsResponse struct {
+	Batch Batch             `json:"batch"`
+	Items []BatchItemResult `json:"items"`
+}
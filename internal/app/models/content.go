@@ -0,0 +1,38 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// TermCount is a keyword or n-gram together with its number of occurrences
+type TermCount struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// ContentAnalysis holds the text statistics of the visible page content
+type ContentAnalysis struct {
+	WordCount           int         `json:"word_count"`
+	SentenceCount       int         `json:"sentence_count"`
+	AvgWordsPerSentence float64     `json:"avg_words_per_sentence"`
+	ReadabilityScore    float64     `json:"readability_score"`
+	ReadabilityLevel    string      `json:"readability_level"`
+	TopKeywords         []TermCount `json:"top_keywords"`
+	TopBigrams          []TermCount `json:"top_bigrams"`
+	TopTrigrams         []TermCount `json:"top_trigrams"`
+	DetectedLanguage    string      `json:"detected_language"`
+	LanguageConfidence  float64     `json:"language_confidence"`
+	DeclaredLanguage    string      `json:"declared_language"`
+	LanguageMismatch    bool        `json:"language_mismatch"`
+}
+
+// Scan implements the sql.Scanner interface
+func (a *ContentAnalysis) Scan(value interface{}) error {
+	return scanJSON(value, a)
+}
+
+// Value implements the driver.Valuer interface
+func (a ContentAnalysis) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
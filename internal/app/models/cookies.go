@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// Cookie issues
+const (
+	CookieSameSiteNoneInsecure = "samesite_none_without_secure"
+	CookieSessionNotHTTPOnly   = "session_without_httponly"
+	CookieSetOverHTTP          = "set_over_http"
+	CookieInvalidPrefix        = "invalid_prefix"
+	CookieOversized            = "oversized"
+)
+
+// ResponseCookie is a cookie set by a Set-Cookie header of the page fetch or
+// its redirect chain. The value is not kept, only its size.
+type ResponseCookie struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// Expires is the absolute expiry and MaxAge the Max-Age in seconds; a
+	// cookie with neither is a session cookie
+	Expires  *time.Time `json:"expires,omitempty"`
+	MaxAge   *int       `json:"max_age,omitempty"`
+	Session  bool       `json:"session"`
+	Secure   bool       `json:"secure"`
+	HttpOnly bool       `json:"http_only"`
+	SameSite string     `json:"same_site,omitempty"`
+	// Size is the length of the name and value in bytes
+	Size int `json:"size"`
+	// SetBy is the URL of the response that set the cookie
+	SetBy  string   `json:"set_by"`
+	Issues []string `json:"issues"`
+}
+
+// CookieSummary counts the cookies of a crawl and their issues
+type CookieSummary struct {
+	Total    int            `json:"total"`
+	Insecure int            `json:"insecure"`
+	ByIssue  map[string]int `json:"by_issue"`
+}
+
+// CookieReport is the outcome of the cookie analysis of a crawl
+type CookieReport struct {
+	Summary CookieSummary    `json:"summary"`
+	Cookies []ResponseCookie `json:"cookies"`
+}
+
+// Scan implements the sql.Scanner interface
+func (r *CookieReport) Scan(value interface{}) error {
+	return scanJSON(value, r)
+}
+
+// Value implements the driver.Valuer interface
+func (r CookieReport) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+type CookiesResponse struct {
+	CrawlID uint   `json:"crawl_id"`
+	URL     string `json:"url"`
+	CookieReport
+}
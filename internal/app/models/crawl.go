@@ -7,6 +7,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// CrawlOptions tunes a single crawl. FreshLinkChecks bypasses the link
+// status cache.
+type CrawlOptions struct {
+	FreshLinkChecks bool
+}
+
 type CrawlRequest struct {
 	URL string `json:"url" binding:"required,url"`
 }
@@ -34,17 +40,59 @@ func (h HeadingCounts) Value() (driver.Value, error) {
 	return json.Marshal(h)
 }
 
+// Crawl run status values. A run is stored as running when the crawl starts
+// and is filled in once the page has been fetched and analyzed.
+const (
+	CrawlStatusRunning   = "running"
+	CrawlStatusCompleted = "completed"
+	CrawlStatusFailed    = "failed"
+)
+
+// Sources of the HTML of a run
+const (
+	SourceTypeCrawl  = "crawl"
+	SourceTypeUpload = "upload"
+)
+
 type CrawlResult struct {
 	gorm.Model
+	Status            string       `json:"status" gorm:"size:20;not null;default:'completed';index"`
+	Error             string       `json:"error,omitempty" gorm:"type:text"`
 	URL               string       `json:"url" gorm:"type:varchar(2000);not null"`
+	SourceType        string       `json:"source_type" gorm:"size:20;not null;default:'crawl';index"`
 	HTMLVersion       string       `json:"html_version" gorm:"size:50"`
+	Doctype           DoctypeInfo  `json:"doctype" gorm:"type:JSON"`
+	Markup            MarkupReport `json:"markup" gorm:"type:JSON"`
 	PageTitle         string       `json:"page_title" gorm:"type:text"`
 	Headings          HeadingCounts `json:"headings" gorm:"type:JSON"`
+	Outline           HeadingOutline `json:"outline" gorm:"type:JSON"`
 	InternalLinks     int          `json:"internal_links" gorm:"default:0"`
 	ExternalLinks     int          `json:"external_links" gorm:"default:0"`
 	InaccessibleLinks StringSlice  `json:"inaccessible_links" gorm:"type:JSON"`
+	Links             LinkAnalysis `json:"links" gorm:"type:JSON"`
 	HasLoginForm      bool         `json:"has_login_form" gorm:"default:false"`
+	Forms             FormInventory `json:"forms" gorm:"type:JSON"`
+	Technologies      TechnologyList `json:"technologies" gorm:"type:JSON"`
+	ThirdParties      ThirdPartyReport `json:"third_parties" gorm:"type:JSON"`
+	Cookies           CookieReport `json:"cookies" gorm:"type:JSON"`
+	Metadata          PageMetadata `json:"metadata" gorm:"type:JSON"`
+	// HealthScore is nil for runs that were never scored
+	HealthScore       *float64     `json:"health_score" gorm:"index"`
+	ScoreBreakdown    ScoreBreakdown `json:"score_breakdown" gorm:"type:JSON"`
+	Content           ContentAnalysis `json:"content" gorm:"type:JSON"`
+	// Flattened copies of the content analysis used for list filtering
+	WordCount         int          `json:"-" gorm:"default:0;index"`
+	ReadabilityScore  float64      `json:"-" gorm:"default:0"`
+	Language          string       `json:"-" gorm:"size:10;index"`
+	LanguageMismatch  bool         `json:"-" gorm:"default:false"`
+	// Lower-cased technology names as ",name,name," for list filtering
+	TechnologyNames   string       `json:"-" gorm:"type:text"`
+	ContentFingerprint string      `json:"content_fingerprint" gorm:"size:16;index"`
+	SnapshotHash      string       `json:"snapshot_hash,omitempty" gorm:"size:64;index"`
 	UserID            uint64       `json:"user_id" gorm:"index;not null"`
+	TrackedURLID      uint         `json:"tracked_url_id" gorm:"index"`
+	Pinned            bool         `json:"pinned" gorm:"default:false"`
+	FreshLinkChecks   bool         `json:"fresh_link_checks" gorm:"default:false"`
 	User              User         `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
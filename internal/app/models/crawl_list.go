@@ -4,13 +4,31 @@ import "time"
 
 type CrawlListResponse struct {
 	ID              uint      `json:"id"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	TrackedURLID    uint      `json:"tracked_url_id"`
+	Pinned          bool      `json:"pinned"`
 	URL             string    `json:"url"`
+	SourceType      string    `json:"source_type"`
 	PageTitle       string    `json:"page_title"`
 	CreatedAt       time.Time `json:"created_at"`
 	HTMLVersion     string    `json:"html_version"`
+	Doctype         DoctypeInfo `json:"doctype"`
+	Markup          MarkupSummary `json:"markup"`
 	Headings        HeadingCounts `json:"headings"`
 	InternalLinks   int       `json:"internal_links"`
 	ExternalLinks   int       `json:"external_links"`
 	InaccessibleLinks StringSlice `json:"inaccessible_links"`
+	LinkSchemes     map[string]int `json:"link_schemes"`
+	BrokenAnchors   []string  `json:"broken_anchors"`
 	HasLoginForm    bool      `json:"has_login_form"`
+	Technologies    TechnologyList `json:"technologies"`
+	ThirdParties    ThirdPartySummary `json:"third_parties"`
+	Cookies         CookieSummary `json:"cookies"`
+	HealthScore     *float64  `json:"health_score"`
+	ScoreBreakdown  ScoreBreakdown `json:"score_breakdown"`
+	WordCount       int       `json:"word_count"`
+	ReadabilityScore float64  `json:"readability_score"`
+	Language        string    `json:"language"`
+	LanguageMismatch bool     `json:"language_mismatch"`
 }
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// CrawlRef identifies one side of a crawl diff
+type CrawlRef struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type StringChange struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Changed bool   `json:"changed"`
+}
+
+type BoolChange struct {
+	From    bool `json:"from"`
+	To      bool `json:"to"`
+	Changed bool `json:"changed"`
+}
+
+// IntDelta is a numeric value of both crawls and the difference To - From
+type IntDelta struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Delta int `json:"delta"`
+}
+
+type HeadingDeltas struct {
+	H1 IntDelta `json:"h1"`
+	H2 IntDelta `json:"h2"`
+	H3 IntDelta `json:"h3"`
+	H4 IntDelta `json:"h4"`
+	H5 IntDelta `json:"h5"`
+	H6 IntDelta `json:"h6"`
+}
+
+// CrawlDiff is the structured difference between two crawls of the same URL
+type CrawlDiff struct {
+	URL                      string        `json:"url"`
+	From                     CrawlRef      `json:"from"`
+	To                       CrawlRef      `json:"to"`
+	Changed                  bool          `json:"changed"`
+	PageTitle                StringChange  `json:"page_title"`
+	HTMLVersion              StringChange  `json:"html_version"`
+	Headings                 HeadingDeltas `json:"headings"`
+	InternalLinks            IntDelta      `json:"internal_links"`
+	ExternalLinks            IntDelta      `json:"external_links"`
+	InaccessibleLinksAdded   []string      `json:"inaccessible_links_added"`
+	InaccessibleLinksRemoved []string      `json:"inaccessible_links_removed"`
+	HasLoginForm             BoolChange    `json:"has_login_form"`
+	// HTMLDiff is a unified diff of the stored HTML snapshots, included on request
+	HTMLDiff *string `json:"html_diff,omitempty"`
+	// HTMLDiffError explains why a requested HTML diff is not available
+	HTMLDiffError string `json:"html_diff_error,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Document rendering modes triggered by the doctype, as defined by the
+// WHATWG HTML standard
+const (
+	DocumentModeStandards     = "standards"
+	DocumentModeLimitedQuirks = "limited-quirks"
+	DocumentModeQuirks        = "quirks"
+)
+
+// XHTMLCheck holds the XHTML-specific checks of a page. Error describes
+// the first well-formedness error, if any.
+type XHTMLCheck struct {
+	Namespace      string `json:"namespace"`
+	NamespaceValid bool   `json:"namespace_valid"`
+	WellFormed     bool   `json:"well_formed"`
+	Error          string `json:"error,omitempty"`
+	ErrorLine      int    `json:"error_line,omitempty"`
+}
+
+// DoctypeInfo describes the doctype of a page. The identifiers are stored
+// as written; Version is the label also reported as html_version. XHTML is
+// set for pages that declare themselves XHTML.
+type DoctypeInfo struct {
+	Present      bool        `json:"present"`
+	Name         string      `json:"name"`
+	PublicID     string      `json:"public_id"`
+	SystemID     string      `json:"system_id"`
+	Version      string      `json:"version"`
+	DocumentMode string      `json:"document_mode"`
+	XHTML        *XHTMLCheck `json:"xhtml,omitempty"`
+}
+
+// Scan implements the sql.Scanner interface
+func (d *DoctypeInfo) Scan(value interface{}) error {
+	return scanJSON(value, d)
+}
+
+// Value implements the driver.Valuer interface
+func (d DoctypeInfo) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
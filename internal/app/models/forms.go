@@ -0,0 +1,57 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// FormField is a named control submitted with a form
+type FormField struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Required     bool   `json:"required"`
+	Autocomplete string `json:"autocomplete,omitempty"`
+}
+
+// Form describes a <form> of a page. Position is the 1-based index of the
+// form in document order and Action the absolute URL it submits to.
+// Autocomplete is the form-level setting ("on" unless disabled).
+type Form struct {
+	Position      int         `json:"position"`
+	ID            string      `json:"id,omitempty"`
+	Name          string      `json:"name,omitempty"`
+	Method        string      `json:"method"`
+	Action        string      `json:"action"`
+	Enctype       string      `json:"enctype"`
+	Fields        []FormField `json:"fields"`
+	HasCSRFToken  bool        `json:"has_csrf_token"`
+	CSRFField     string      `json:"csrf_field,omitempty"`
+	Autocomplete  string      `json:"autocomplete"`
+	HasFileUpload bool        `json:"has_file_upload"`
+	HasPassword   bool        `json:"has_password"`
+	CrossOrigin   bool        `json:"cross_origin"`
+	Insecure      bool        `json:"insecure"`
+}
+
+// FormInventory holds every form of a page
+type FormInventory []Form
+
+// Scan implements the sql.Scanner interface
+func (f *FormInventory) Scan(value interface{}) error {
+	return scanJSON(value, f)
+}
+
+// Value implements the driver.Valuer interface
+func (f FormInventory) Value() (driver.Value, error) {
+	if f == nil {
+		f = FormInventory{}
+	}
+	return json.Marshal(f)
+}
+
+type FormsResponse struct {
+	CrawlID uint   `json:"crawl_id"`
+	URL     string `json:"url"`
+	Count   int    `json:"count"`
+	Forms   []Form `json:"forms"`
+}
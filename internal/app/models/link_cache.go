@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// LinkStatus is the outcome of checking a link. Cached is set when it was
+// served from the link status cache instead of a request.
+type LinkStatus struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Cached     bool   `json:"cached"`
+}
+
+// Accessible reports whether the link could be fetched
+func (s LinkStatus) Accessible() bool {
+	return s.Error == "" && s.StatusCode < 400
+}
+
+// CachedLinkStatus is the shared, persistent tier of the link status cache.
+// URLHash is the SHA-256 of the normalized URL.
+type CachedLinkStatus struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	URLHash    string    `json:"url_hash" gorm:"size:64;not null;uniqueIndex"`
+	URL        string    `json:"url" gorm:"type:varchar(2000);not null"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error" gorm:"type:text"`
+	CheckedAt  time.Time `json:"checked_at" gorm:"not null"`
+	ExpiresAt  time.Time `json:"expires_at" gorm:"not null;index"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// LinkEdge is a hyperlink found on a crawled page. URLs are absolute and
+// normalized, without fragment.
+type LinkEdge struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CrawlID    uint      `json:"crawl_id" gorm:"index;not null"`
+	UserID     uint64    `json:"user_id" gorm:"index;not null"`
+	SourceURL  string    `json:"source_url" gorm:"type:varchar(2000);not null"`
+	TargetURL  string    `json:"target_url" gorm:"type:varchar(2000);not null"`
+	TargetHost string    `json:"target_host" gorm:"size:255;index"`
+	AnchorText string    `json:"anchor_text" gorm:"type:varchar(500)"`
+	Rel        string    `json:"rel" gorm:"size:255"`
+	Internal   bool      `json:"internal"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// GraphNode is a page of a site's link graph. Pages that are linked to but
+// were not crawled have no outlinks of their own.
+type GraphNode struct {
+	URL               string  `json:"url"`
+	Crawled           bool    `json:"crawled"`
+	CrawlID           uint    `json:"crawl_id,omitempty"`
+	InDegree          int     `json:"in_degree"`
+	OutDegree         int     `json:"out_degree"`
+	InternalOutDegree int     `json:"internal_out_degree"`
+	ExternalOutDegree int     `json:"external_out_degree"`
+	Depth             *int    `json:"depth"`
+	PageRank          float64 `json:"pagerank"`
+}
+
+// GraphEdge is a distinct source to target link. Count is the number of
+// anchors on the source page pointing at the target.
+type GraphEdge struct {
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	AnchorText string `json:"anchor_text"`
+	Rel        string `json:"rel,omitempty"`
+	Internal   bool   `json:"internal"`
+	Count      int    `json:"count"`
+}
+
+// LinkGraph is the hyperlink graph of a site built from the latest crawl of
+// each of its pages, with its analytics
+type LinkGraph struct {
+	Host        string      `json:"host"`
+	StartURL    string      `json:"start_url"`
+	Pages       int         `json:"pages"`
+	Nodes       []GraphNode `json:"nodes"`
+	Edges       []GraphEdge `json:"edges"`
+	Orphans     []string    `json:"orphans"`
+	NoOutlinks  []string    `json:"no_outlinks"`
+	Unreachable []string    `json:"unreachable"`
+	MaxDepth    int         `json:"max_depth"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Link issue types reported by the link classification
+const (
+	LinkIssueInvalidMailto  = "invalid_mailto"
+	LinkIssueJavascriptLink = "javascript_link"
+)
+
+// LinkIssue is a link that is not broken but is likely a mistake or hurts
+// accessibility and SEO
+type LinkIssue struct {
+	Type    string `json:"type"`
+	Href    string `json:"href"`
+	Message string `json:"message"`
+}
+
+// LinkAnalysis holds the number of links per URL scheme, the issues found
+// with non-HTTP links, the links whose fragment matches no anchor of the
+// target page and the outcome of every HTTP(S) link check. Relative links
+// are counted under the scheme they resolve to.
+type LinkAnalysis struct {
+	Schemes       map[string]int `json:"schemes"`
+	Issues        []LinkIssue    `json:"issues"`
+	BrokenAnchors []string       `json:"broken_anchors"`
+	Checks        []LinkStatus   `json:"checks"`
+}
+
+// Scan implements the sql.Scanner interface
+func (l *LinkAnalysis) Scan(value interface{}) error {
+	return scanJSON(value, l)
+}
+
+// Value implements the driver.Valuer interface
+func (l LinkAnalysis) Value() (driver.Value, error) {
+	if l.Schemes == nil {
+		l.Schemes = map[string]int{}
+	}
+	if l.Issues == nil {
+		l.Issues = []LinkIssue{}
+	}
+	if l.BrokenAnchors == nil {
+		l.BrokenAnchors = []string{}
+	}
+	if l.Checks == nil {
+		l.Checks = []LinkStatus{}
+	}
+	return json.Marshal(l)
+}
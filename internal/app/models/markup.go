@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Markup finding severities
+const (
+	MarkupSeverityError   = "error"
+	MarkupSeverityWarning = "warning"
+)
+
+// Markup finding types reported by the conformance checks
+const (
+	MarkupDuplicateID        = "duplicate_id"
+	MarkupDuplicateAttribute = "duplicate_attribute"
+	MarkupObsoleteElement    = "obsolete_element"
+	MarkupObsoleteAttribute  = "obsolete_attribute"
+	MarkupMisnestedTag       = "misnested_tag"
+	MarkupUnclosedTag        = "unclosed_tag"
+	MarkupStrayEndTag        = "stray_end_tag"
+	MarkupMissingAttribute   = "missing_attribute"
+	MarkupMissingTitle       = "missing_title"
+	MarkupMultipleTitles     = "multiple_titles"
+	MarkupEmptyTitle         = "empty_title"
+	MarkupMissingCharset     = "missing_charset"
+	MarkupMultipleCharsets   = "multiple_charsets"
+	MarkupLateCharset        = "late_charset"
+	MarkupNonUTF8Charset     = "non_utf8_charset"
+)
+
+// MarkupFinding is a conformance problem found in the markup of a page.
+// Line and Column are 1-based and point at the start of the offending tag,
+// or are 0 for problems with the document as a whole.
+type MarkupFinding struct {
+	Type     string `json:"type"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Element  string `json:"element,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+// MarkupSummary counts the findings of a page by severity and type.
+// Truncated is set when only the first findings were kept.
+type MarkupSummary struct {
+	Errors    int            `json:"errors"`
+	Warnings  int            `json:"warnings"`
+	ByType    map[string]int `json:"by_type"`
+	Truncated bool           `json:"truncated"`
+}
+
+// MarkupReport is the outcome of the markup conformance checks of a page
+type MarkupReport struct {
+	Summary  MarkupSummary   `json:"summary"`
+	Findings []MarkupFinding `json:"findings"`
+}
+
+// Scan implements the sql.Scanner interface
+func (r *MarkupReport) Scan(value interface{}) error {
+	return scanJSON(value, r)
+}
+
+// Value implements the driver.Valuer interface
+func (r MarkupReport) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+type MarkupResponse struct {
+	CrawlID  uint            `json:"crawl_id"`
+	URL      string          `json:"url"`
+	Summary  MarkupSummary   `json:"summary"`
+	Findings []MarkupFinding `json:"findings"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// SecurityHeaders lists which recommended security headers the page response
+// sent. Checked is false for pages without a response, such as uploads.
+type SecurityHeaders struct {
+	Checked bool     `json:"checked"`
+	Present []string `json:"present"`
+	Missing []string `json:"missing"`
+}
+
+// PageMetadata holds the document metadata and response headers used to
+// score a page
+type PageMetadata struct {
+	Description     string          `json:"description"`
+	Viewport        string          `json:"viewport"`
+	Canonical       string          `json:"canonical"`
+	SecurityHeaders SecurityHeaders `json:"security_headers"`
+}
+
+// Scan implements the sql.Scanner interface
+func (m *PageMetadata) Scan(value interface{}) error {
+	return scanJSON(value, m)
+}
+
+// Value implements the driver.Valuer interface
+func (m PageMetadata) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
@@ -0,0 +1,16 @@
+package models
+
+// HostStats is the state of the outbound requests to a crawled host.
+// Waiting requests are queued behind the host's politeness limits.
+type HostStats struct {
+	Host               string `json:"host"`
+	Waiting            int    `json:"waiting"`
+	Active             int    `json:"active"`
+	DelayMS            int64  `json:"delay_ms"`
+	RobotsCrawlDelayMS int64  `json:"robots_crawl_delay_ms"`
+}
+
+type MetricsResponse struct {
+	CrawlQueueDepth int         `json:"crawl_queue_depth"`
+	Hosts           []HostStats `json:"hosts"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Outline issue types reported by the heading validation
+const (
+	OutlineIssueMissingH1    = "missing_h1"
+	OutlineIssueMultipleH1   = "multiple_h1"
+	OutlineIssueSkippedLevel = "skipped_level"
+	OutlineIssueEmptyHeading = "empty_heading"
+)
+
+// HeadingNode is a single heading in the document outline. Position is the
+// 1-based index of the heading in document order.
+type HeadingNode struct {
+	Level    int            `json:"level"`
+	Text     string         `json:"text"`
+	Position int            `json:"position"`
+	Children []*HeadingNode `json:"children,omitempty"`
+}
+
+// OutlineIssue describes a problem found while validating the outline.
+// Position is 0 for issues that concern the document as a whole.
+type OutlineIssue struct {
+	Type     string `json:"type"`
+	Message  string `json:"message"`
+	Position int    `json:"position,omitempty"`
+}
+
+// HeadingOutline is the ordered heading tree of a page
+type HeadingOutline struct {
+	Headings []*HeadingNode `json:"headings"`
+	Issues   []OutlineIssue `json:"issues"`
+}
+
+// Scan implements the sql.Scanner interface
+func (o *HeadingOutline) Scan(value interface{}) error {
+	return scanJSON(value, o)
+}
+
+// Value implements the driver.Valuer interface
+func (o HeadingOutline) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+type OutlineResponse struct {
+	CrawlID  uint           `json:"crawl_id"`
+	URL      string         `json:"url"`
+	Headings HeadingCounts  `json:"headings"`
+	Outline  []*HeadingNode `json:"outline"`
+	Issues   []OutlineIssue `json:"issues"`
+}
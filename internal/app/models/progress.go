@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Crawl progress event types streamed by GET /crawls/:id/events
+const (
+	ProgressFetchStarted     = "fetch_started"
+	ProgressPageFetched      = "page_fetched"
+	ProgressLinksDiscovered  = "links_discovered"
+	ProgressLinkChecked      = "link_checked"
+	ProgressAnalysisFinished = "analysis_finished"
+	ProgressCompleted        = "completed"
+	ProgressFailed           = "failed"
+)
+
+// ProgressEvent is one step of a running crawl. IDs increase by one per
+// crawl and are used as SSE event IDs, so clients can resume a stream with
+// Last-Event-ID.
+type ProgressEvent struct {
+	ID      int         `json:"id"`
+	CrawlID uint        `json:"crawl_id"`
+	Type    string      `json:"type"`
+	Time    time.Time   `json:"time"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// AsyncCrawlResponse is returned when a crawl is started in the background
+type AsyncCrawlResponse struct {
+	ID           uint   `json:"id"`
+	TrackedURLID uint   `json:"tracked_url_id"`
+	URL          string `json:"url"`
+	Status       string `json:"status"`
+	EventsURL    string `json:"events_url"`
+}
+
+// PageFetchedData is the data of page_fetched events
+type PageFetchedData struct {
+	StatusCode  int    `json:"status_code"`
+	Bytes       int    `json:"bytes"`
+	ContentType string `json:"content_type"`
+}
+
+// LinksDiscoveredData is the data of links_discovered events
+type LinksDiscoveredData struct {
+	Total int `json:"total"`
+}
+
+// LinkCheckedData is the data of link_checked events
+type LinkCheckedData struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Accessible bool   `json:"accessible"`
+	Error      string `json:"error,omitempty"`
+	Cached     bool   `json:"cached"`
+}
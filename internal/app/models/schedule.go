@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Schedule status values recorded after each scheduled crawl
+const (
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusFailed    = "failed"
+)
+
+// Schedule periodically recrawls a tracked URL, either following a cron
+// expression evaluated in Timezone or at a fixed interval
+type Schedule struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint64     `json:"user_id" gorm:"index;not null"`
+	TrackedURLID    uint       `json:"tracked_url_id" gorm:"index;not null"`
+	URL             string     `json:"url" gorm:"type:varchar(2000);not null"`
+	CronExpression  string     `json:"cron_expression,omitempty" gorm:"size:100"`
+	IntervalSeconds int        `json:"interval_seconds,omitempty" gorm:"default:0"`
+	Timezone        string     `json:"timezone" gorm:"size:64;default:UTC"`
+	Enabled         bool       `json:"enabled" gorm:"not null"`
+	NextRunAt       *time.Time `json:"next_run_at" gorm:"index"`
+	LastRunAt       *time.Time `json:"last_run_at"`
+	LastStatus      string     `json:"last_status,omitempty" gorm:"size:20"`
+	LastError       string     `json:"last_error,omitempty" gorm:"type:text"`
+	LastCrawlID     *uint      `json:"last_crawl_id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	User            User       `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type CreateScheduleRequest struct {
+	URL             string `json:"url" binding:"required,url"`
+	CronExpression  string `json:"cron_expression"`
+	IntervalSeconds int    `json:"interval_seconds"`
+	Timezone        string `json:"timezone"`
+	Enabled         *bool  `json:"enabled"`
+}
+
+type UpdateScheduleRequest struct {
+	CronExpression  *string `json:"cron_expression"`
+	IntervalSeconds *int    `json:"interval_seconds"`
+	Timezone        *string `json:"timezone"`
+	Enabled         *bool   `json:"enabled"`
+}
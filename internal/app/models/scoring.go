@@ -0,0 +1,116 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// Scoring rules of the page health score
+const (
+	ScoreRuleBrokenLinks     = "broken_links"
+	ScoreRuleHeadings        = "heading_structure"
+	ScoreRuleTitle           = "title"
+	ScoreRuleMetaDescription = "meta_description"
+	ScoreRuleLanguage        = "language"
+	ScoreRuleViewport        = "viewport"
+	ScoreRuleSecurityHeaders = "security_headers"
+	ScoreRuleMarkup          = "markup"
+	ScoreRuleCookies         = "cookies"
+	ScoreRuleIntegrity       = "subresource_integrity"
+)
+
+// ScoringRule is the weight of a scoring rule and, for rules that use one,
+// its threshold. A weight of 0 disables the rule.
+type ScoringRule struct {
+	Weight    float64 `json:"weight"`
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+// ScoringRules maps rule names to their settings
+type ScoringRules map[string]ScoringRule
+
+// Scan implements the sql.Scanner interface
+func (r *ScoringRules) Scan(value interface{}) error {
+	return scanJSON(value, r)
+}
+
+// Value implements the driver.Valuer interface
+func (r ScoringRules) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(r)
+}
+
+// ScoringProfile overrides the default weights and thresholds of the health
+// score. The user's default profile scores their crawls; rules it does not
+// list keep their defaults.
+type ScoringProfile struct {
+	ID        uint         `json:"id" gorm:"primaryKey"`
+	UserID    uint64       `json:"user_id" gorm:"index;not null"`
+	Name      string       `json:"name" gorm:"size:100;not null"`
+	Rules     ScoringRules `json:"rules" gorm:"type:JSON"`
+	IsDefault bool         `json:"is_default" gorm:"not null;default:false"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	User      User         `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// ScoreItem is the outcome of one scoring rule. Value is between 0 and 1 and
+// Points is its share of the 100 point score. Rules that do not apply to a
+// page, such as response checks of an upload, are left out of the score.
+type ScoreItem struct {
+	Rule       string  `json:"rule"`
+	Weight     float64 `json:"weight"`
+	Threshold  float64 `json:"threshold,omitempty"`
+	Applicable bool    `json:"applicable"`
+	Value      float64 `json:"value"`
+	Points     float64 `json:"points"`
+	MaxPoints  float64 `json:"max_points"`
+	Detail     string  `json:"detail"`
+}
+
+// ScoreBreakdown is the health score of a page and how it was computed.
+// ProfileID is nil when the default weights were used.
+type ScoreBreakdown struct {
+	ProfileID   *uint       `json:"profile_id"`
+	ProfileName string      `json:"profile_name"`
+	Score       float64     `json:"score"`
+	Items       []ScoreItem `json:"items"`
+}
+
+// Scan implements the sql.Scanner interface
+func (b *ScoreBreakdown) Scan(value interface{}) error {
+	return scanJSON(value, b)
+}
+
+// Value implements the driver.Valuer interface
+func (b ScoreBreakdown) Value() (driver.Value, error) {
+	return json.Marshal(b)
+}
+
+type CreateScoringProfileRequest struct {
+	Name      string       `json:"name" binding:"required"`
+	Rules     ScoringRules `json:"rules"`
+	IsDefault *bool        `json:"is_default"`
+}
+
+type UpdateScoringProfileRequest struct {
+	Name      *string      `json:"name"`
+	Rules     ScoringRules `json:"rules"`
+	IsDefault *bool        `json:"is_default"`
+}
+
+// ScoringProfileResponse is a scoring profile and whether the user's crawls
+// are being rescored in the background after it changed
+type ScoringProfileResponse struct {
+	ScoringProfile
+	Rescoring bool `json:"rescoring"`
+}
+
+type ScoreResponse struct {
+	CrawlID uint   `json:"crawl_id"`
+	URL     string `json:"url"`
+	ScoreBreakdown
+}
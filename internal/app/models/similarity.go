@@ -0,0 +1,37 @@
+package models
+
+// SimilarCrawl is another crawl whose content is close to the requested one
+type SimilarCrawl struct {
+	ID         uint    `json:"id"`
+	URL        string  `json:"url"`
+	PageTitle  string  `json:"page_title"`
+	Similarity float64 `json:"similarity"`
+}
+
+type SimilarCrawlsResponse struct {
+	CrawlID   uint           `json:"crawl_id"`
+	URL       string         `json:"url"`
+	Threshold float64        `json:"threshold"`
+	Similar   []SimilarCrawl `json:"similar"`
+}
+
+// DuplicatePage is a member of a near-duplicate cluster
+type DuplicatePage struct {
+	ID        uint   `json:"id"`
+	URL       string `json:"url"`
+	PageTitle string `json:"page_title"`
+}
+
+// DuplicateCluster is a group of pages serving nearly identical content.
+// MinSimilarity is the lowest pairwise similarity inside the cluster.
+type DuplicateCluster struct {
+	Pages         []DuplicatePage `json:"pages"`
+	MinSimilarity float64         `json:"min_similarity"`
+}
+
+type DuplicateReportResponse struct {
+	Host          string             `json:"host,omitempty"`
+	Threshold     float64            `json:"threshold"`
+	PagesAnalyzed int                `json:"pages_analyzed"`
+	Clusters      []DuplicateCluster `json:"clusters"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Snapshot records a stored HTML blob. Blobs are content-addressed by the
+// SHA-256 of the uncompressed HTML, so identical pages share one snapshot.
+// ReferencedAt is the last time a crawl stored the snapshot; blobs no crawl
+// references are only deleted once it is old enough.
+type Snapshot struct {
+	Hash           string    `json:"hash" gorm:"primaryKey;size:64"`
+	Size           int64     `json:"size"`
+	CompressedSize int64     `json:"compressed_size"`
+	CreatedAt      time.Time `json:"created_at"`
+	ReferencedAt   time.Time `json:"referenced_at" gorm:"index"`
+}
+
+type StorageUsageResponse struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+	Snapshots  int64 `json:"snapshots"`
+}
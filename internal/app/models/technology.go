@@ -0,0 +1,29 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Technology is a technology a crawled site was detected to be built with
+type Technology struct {
+	Name       string   `json:"name"`
+	Version    string   `json:"version,omitempty"`
+	Categories []string `json:"categories"`
+	// Implied is set when the technology was not matched itself but is
+	// implied by one that was, such as PHP for WordPress
+	Implied bool `json:"implied,omitempty"`
+}
+
+// TechnologyList is the list of technologies detected on a page
+type TechnologyList []Technology
+
+// Scan implements the sql.Scanner interface
+func (l *TechnologyList) Scan(value interface{}) error {
+	return scanJSON(value, l)
+}
+
+// Value implements the driver.Valuer interface
+func (l TechnologyList) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
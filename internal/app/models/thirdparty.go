@@ -0,0 +1,87 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Third-party resource types
+const (
+	ResourceTypeScript     = "script"
+	ResourceTypeStylesheet = "stylesheet"
+	ResourceTypeIframe     = "iframe"
+	ResourceTypePixel      = "pixel"
+)
+
+// ThirdPartyUncategorized is the category of third-party domains that are
+// not on the tracker list
+const ThirdPartyUncategorized = "Uncategorized"
+
+// Subresource Integrity issues
+const (
+	SRIMissing = "missing"
+	SRIInvalid = "invalid"
+)
+
+// ThirdPartyResource is a script, stylesheet, iframe or tracking pixel a
+// page loads from another site
+type ThirdPartyResource struct {
+	URL      string `json:"url"`
+	Domain   string `json:"domain"`
+	Type     string `json:"type"`
+	Category string `json:"category"`
+	Company  string `json:"company,omitempty"`
+	// Integrity is set when the resource carries a valid integrity attribute
+	Integrity bool `json:"integrity,omitempty"`
+}
+
+// ThirdPartyDomain groups the resources a page loads from one domain
+type ThirdPartyDomain struct {
+	Domain    string         `json:"domain"`
+	Category  string         `json:"category"`
+	Company   string         `json:"company,omitempty"`
+	Resources int            `json:"resources"`
+	Types     map[string]int `json:"types"`
+}
+
+// SRIIssue is an external script or stylesheet whose Subresource Integrity
+// attribute is missing or malformed
+type SRIIssue struct {
+	URL   string `json:"url"`
+	Type  string `json:"type"`
+	Issue string `json:"issue"`
+}
+
+// ThirdPartySummary counts the third parties of a page. ByCategory counts
+// domains per category.
+type ThirdPartySummary struct {
+	Domains          int            `json:"domains"`
+	Resources        int            `json:"resources"`
+	Trackers         int            `json:"trackers"`
+	ByCategory       map[string]int `json:"by_category"`
+	MissingIntegrity int            `json:"missing_integrity"`
+}
+
+// ThirdPartyReport is the inventory of the third parties a page loads
+type ThirdPartyReport struct {
+	Summary   ThirdPartySummary    `json:"summary"`
+	Domains   []ThirdPartyDomain   `json:"domains"`
+	Resources []ThirdPartyResource `json:"resources"`
+	SRIIssues []SRIIssue           `json:"sri_issues"`
+}
+
+// Scan implements the sql.Scanner interface
+func (r *ThirdPartyReport) Scan(value interface{}) error {
+	return scanJSON(value, r)
+}
+
+// Value implements the driver.Valuer interface
+func (r ThirdPartyReport) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+type ThirdPartyResponse struct {
+	CrawlID uint   `json:"crawl_id"`
+	URL     string `json:"url"`
+	ThirdPartyReport
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// TrackedURL is a URL a user has crawled. Every crawl of the URL is stored as
+// a separate CrawlResult run; the tracked URL points at the latest one.
+// URL is canonical and URLHash its SHA-256, unique per user.
+type TrackedURL struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint64     `json:"user_id" gorm:"index;not null"`
+	URL           string     `json:"url" gorm:"type:varchar(2000);not null"`
+	URLHash       string     `json:"-" gorm:"size:64;not null;default:''"`
+	LatestRunID   *uint      `json:"latest_run_id"`
+	RunCount      int        `json:"run_count" gorm:"default:0"`
+	LastCrawledAt *time.Time `json:"last_crawled_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	User          User       `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type TrackedURLResponse struct {
+	ID            uint               `json:"id"`
+	URL           string             `json:"url"`
+	RunCount      int                `json:"run_count"`
+	LastCrawledAt *time.Time         `json:"last_crawled_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+	LatestRun     *CrawlListResponse `json:"latest_run"`
+}
+
+type RunHistoryResponse struct {
+	TrackedURL TrackedURLResponse  `json:"tracked_url"`
+	Runs       []CrawlListResponse `json:"runs"`
+}
+
+type UpdateRunRequest struct {
+	Pinned *bool `json:"pinned" binding:"required"`
+}
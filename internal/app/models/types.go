@@ -36,3 +36,40 @@ func (ss *StringSlice) Scan(value interface{}) error {
 func (ss StringSlice) Value() (driver.Value, error) {
 	return json.Marshal(ss)
 }
+
+// scanJSON unmarshals a JSON column into dest. NULL columns (e.g. rows
+// created before the column was added) leave dest at its zero value.
+func scanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		if len(v) == 0 {
+			return nil
+		}
+		return json.Unmarshal(v, dest)
+	case string:
+		if v == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("failed to scan JSON value: %v", value)
+	}
+}
+
+// JSONMap is a free-form JSON object stored in a JSON column
+type JSONMap map[string]interface{}
+
+// Scan implements the sql.Scanner interface
+func (m *JSONMap) Scan(value interface{}) error {
+	return scanJSON(value, m)
+}
+
+// Value implements the driver.Valuer interface
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
@@ -0,0 +1,105 @@
+package models
+
+import "time"
+
+// Webhook events
+const (
+	WebhookEventCrawlCompleted = "crawl.completed"
+	WebhookEventCrawlFailed    = "crawl.failed"
+	WebhookEventLinkBroken     = "link.broken"
+	WebhookEventAlertTriggered = "alert.triggered"
+)
+
+// Webhook delivery status values. Pending deliveries are retried with an
+// exponential backoff until they succeed or run out of attempts.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusDead      = "dead"
+)
+
+// Webhook is an endpoint of a user that receives the events it subscribes to.
+// The secret is only returned when the webhook is created.
+type Webhook struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	UserID    uint64      `json:"user_id" gorm:"index;not null"`
+	URL       string      `json:"url" gorm:"type:varchar(2000);not null"`
+	Secret    string      `json:"-" gorm:"size:128;not null"`
+	Events    StringSlice `json:"events" gorm:"type:JSON"`
+	Active    bool        `json:"active" gorm:"not null"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	User      User        `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// WebhookDelivery is one event sent (or to be sent) to a webhook
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	WebhookID      uint       `json:"webhook_id" gorm:"index;not null"`
+	UserID         uint64     `json:"user_id" gorm:"index;not null"`
+	Event          string     `json:"event" gorm:"size:50;not null"`
+	Payload        string     `json:"payload" gorm:"type:mediumtext"`
+	Status         string     `json:"status" gorm:"size:20;not null;index:idx_delivery_due,priority:1"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at" gorm:"index:idx_delivery_due,priority:2"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Webhook        Webhook    `json:"-" gorm:"foreignKey:WebhookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// WebhookEvent is the JSON body posted to webhook endpoints
+type WebhookEvent struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// CrawlEventData is the data of crawl.completed events
+type CrawlEventData struct {
+	CrawlID           uint   `json:"crawl_id"`
+	TrackedURLID      uint   `json:"tracked_url_id"`
+	URL               string `json:"url"`
+	PageTitle         string `json:"page_title"`
+	HTMLVersion       string `json:"html_version"`
+	InternalLinks     int    `json:"internal_links"`
+	ExternalLinks     int    `json:"external_links"`
+	InaccessibleLinks int    `json:"inaccessible_links"`
+	HasLoginForm      bool   `json:"has_login_form"`
+}
+
+// CrawlFailedEventData is the data of crawl.failed events
+type CrawlFailedEventData struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// LinkBrokenEventData is the data of link.broken events
+type LinkBrokenEventData struct {
+	CrawlID      uint     `json:"crawl_id"`
+	TrackedURLID uint     `json:"tracked_url_id"`
+	URL          string   `json:"url"`
+	Links        []string `json:"links"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1"`
+	// Secret is generated when omitted
+	Secret string `json:"secret"`
+	Active *bool  `json:"active"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url" binding:"omitempty,url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// CreateWebhookResponse is the created webhook including its signing secret
+type CreateWebhookResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
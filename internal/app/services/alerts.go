@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"gorm.io/gorm"
+)
+
+// DefaultInternalLinksDropThreshold is the drop percentage used by
+// internal_links_drop rules created without a threshold
+const DefaultInternalLinksDropThreshold = 20.0
+
+// AlertRuleTypes lists the supported alert rule types
+var AlertRuleTypes = map[string]bool{
+	models.AlertRuleNewInaccessibleLink: true,
+	models.AlertRuleTitleChanged:        true,
+	models.AlertRuleLoginFormChanged:    true,
+	models.AlertRuleInternalLinksDrop:   true,
+}
+
+// EvaluateAlertRules compares a freshly stored crawl with the previous
+// completed run of the same tracked URL and stores an alert for every
+// enabled rule it triggers. The first run of a URL has nothing to compare
+// with and never triggers alerts.
+func EvaluateAlertRules(db *gorm.DB, current *models.CrawlResult) ([]models.Alert, error) {
+	var rules []models.AlertRule
+	if err := db.Where("user_id = ? AND enabled = ? AND (tracked_url_id IS NULL OR tracked_url_id = ?)",
+		current.UserID, true, current.TrackedURLID).
+		Order("id").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load alert rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var previous models.CrawlResult
+	err := db.Where("tracked_url_id = ? AND id < ? AND status = ?",
+		current.TrackedURLID, current.ID, models.CrawlStatusCompleted).
+		Order("id DESC").
+		First(&previous).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load previous run: %w", err)
+	}
+
+	diff := DiffCrawls(previous, *current)
+
+	var alerts []models.Alert
+	for _, rule := range rules {
+		message, context, triggered := evaluateRule(rule, diff)
+		if !triggered {
+			continue
+		}
+		alerts = append(alerts, models.Alert{
+			UserID:          current.UserID,
+			RuleID:          rule.ID,
+			TrackedURLID:    current.TrackedURLID,
+			CrawlID:         current.ID,
+			PreviousCrawlID: previous.ID,
+			URL:             current.URL,
+			Type:            rule.Type,
+			Message:         message,
+			Context:         context,
+			Status:          models.AlertStatusOpen,
+		})
+	}
+
+	if len(alerts) > 0 {
+		if err := db.Create(&alerts).Error; err != nil {
+			return nil, fmt.Errorf("failed to store alerts: %w", err)
+		}
+	}
+	return alerts, nil
+}
+
+// evaluateRule checks a single rule against the diff of two runs
+func evaluateRule(rule models.AlertRule, diff models.CrawlDiff) (string, models.JSONMap, bool) {
+	switch rule.Type {
+	case models.AlertRuleNewInaccessibleLink:
+		if len(diff.InaccessibleLinksAdded) == 0 {
+			return "", nil, false
+		}
+		return fmt.Sprintf("%d new inaccessible link(s) found", len(diff.InaccessibleLinksAdded)),
+			models.JSONMap{"links": diff.InaccessibleLinksAdded}, true
+
+	case models.AlertRuleTitleChanged:
+		if !diff.PageTitle.Changed {
+			return "", nil, false
+		}
+		return fmt.Sprintf("Page title changed from %q to %q", diff.PageTitle.From, diff.PageTitle.To),
+			models.JSONMap{"from": diff.PageTitle.From, "to": diff.PageTitle.To}, true
+
+	case models.AlertRuleLoginFormChanged:
+		if !diff.HasLoginForm.Changed {
+			return "", nil, false
+		}
+		message := "Login form removed"
+		if diff.HasLoginForm.To {
+			message = "Login form added"
+		}
+		return message, models.JSONMap{"from": diff.HasLoginForm.From, "to": diff.HasLoginForm.To}, true
+
+	case models.AlertRuleInternalLinksDrop:
+		from, to := diff.InternalLinks.From, diff.InternalLinks.To
+		if from == 0 || to >= from {
+			return "", nil, false
+		}
+		threshold := rule.Threshold
+		if threshold <= 0 {
+			threshold = DefaultInternalLinksDropThreshold
+		}
+		drop := float64(from-to) / float64(from) * 100
+		if drop <= threshold {
+			return "", nil, false
+		}
+		drop = round1(drop)
+		return fmt.Sprintf("Internal links dropped by %.1f%% (%d to %d)", drop, from, to),
+			models.JSONMap{"from": from, "to": to, "drop_percent": drop, "threshold": threshold}, true
+	}
+	return "", nil, false
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// linkCounts is the outcome of countLinks
+type linkCounts struct {
+	internal, external, inaccessible int
+	broken                           []string
+	analysis                         models.LinkAnalysis
+}
+
+// countLinks classifies the links of a page by scheme and checks the HTTP(S)
+// ones through the link status cache, bypassing it when fresh is set.
+// Relative links are resolved against the page URL or its <base href>.
+// Other schemes are never requested; mailto addresses are validated and
+// javascript: links are reported as issues. Fragments of in-page and internal
+// links are validated against the anchors of their target page. onCheck, if
+// set, is called with the outcome of every link check.
+func countLinks(ctx context.Context, doc *goquery.Document, page *url.URL, client *http.Client, links *LinkStatusCache, fresh bool, onCheck func(models.LinkStatus)) linkCounts {
+	counts := linkCounts{
+		analysis: models.LinkAnalysis{
+			Schemes:       make(map[string]int),
+			Issues:        make([]models.LinkIssue, 0),
+			BrokenAnchors: make([]string, 0),
+			Checks:        make([]models.LinkStatus, 0),
+		},
+	}
+
+	base := documentBase(doc, page)
+	anchors := newAnchorChecker(doc, page, client)
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		href = strings.TrimSpace(href)
+		if href == "" {
+			return
+		}
+
+		target, err := base.Parse(href)
+		if err != nil {
+			// Unparseable hrefs cannot be followed
+			counts.broken = append(counts.broken, href)
+			counts.inaccessible++
+			return
+		}
+
+		// In-page links are only checked against the page's own anchors
+		if anchors.samePage(href, target) {
+			if !anchors.valid(ctx, target) {
+				counts.analysis.BrokenAnchors = append(counts.analysis.BrokenAnchors, href)
+			}
+			return
+		}
+
+		// Relative links of an upload without a base URL lead nowhere
+		if target.Scheme == uploadScheme {
+			return
+		}
+
+		scheme := strings.ToLower(target.Scheme)
+		counts.analysis.Schemes[scheme]++
+
+		switch scheme {
+		case "http", "https":
+		case "mailto":
+			if err := validateMailto(target); err != nil {
+				counts.analysis.Issues = append(counts.analysis.Issues, models.LinkIssue{
+					Type:    models.LinkIssueInvalidMailto,
+					Href:    href,
+					Message: err.Error(),
+				})
+			}
+			return
+		case "javascript":
+			counts.analysis.Issues = append(counts.analysis.Issues, models.LinkIssue{
+				Type:    models.LinkIssueJavascriptLink,
+				Href:    href,
+				Message: "javascript: pseudo-link is not a real link for keyboard users, assistive technology or search engines; use a button or a real URL",
+			})
+			return
+		default:
+			// tel:, sms:, data:, ftp: and other schemes are not checked
+			return
+		}
+
+		// Check if link is accessible
+		status := links.Check(ctx, client, target, fresh)
+		status.URL = href
+		counts.analysis.Checks = append(counts.analysis.Checks, status)
+		if onCheck != nil {
+			onCheck(status)
+		}
+		if !status.Accessible() {
+			counts.broken = append(counts.broken, href)
+			counts.inaccessible++
+			return
+		}
+
+		if strings.EqualFold(target.Hostname(), page.Hostname()) {
+			counts.internal++
+			if target.Fragment != "" && !anchors.valid(ctx, target) {
+				counts.analysis.BrokenAnchors = append(counts.analysis.BrokenAnchors, href)
+			}
+		} else {
+			counts.external++
+		}
+	})
+
+	return counts
+}
+
+// validateMailto checks the syntax of the addresses of a mailto: link,
+// including those given in its "to" header field
+func validateMailto(target *url.URL) error {
+	var addresses []string
+	if target.Opaque != "" {
+		opaque, err := url.PathUnescape(target.Opaque)
+		if err != nil {
+			return fmt.Errorf("invalid mailto link: %v", err)
+		}
+		addresses = append(addresses, strings.Split(opaque, ",")...)
+	}
+	for _, to := range target.Query()["to"] {
+		addresses = append(addresses, strings.Split(to, ",")...)
+	}
+
+	found := false
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		found = true
+		parsed, err := mail.ParseAddress(address)
+		if err != nil || !strings.Contains(parsed.Address[strings.LastIndex(parsed.Address, "@")+1:], ".") {
+			return fmt.Errorf("invalid email address %q", address)
+		}
+	}
+	if !found {
+		return fmt.Errorf("mailto link has no email address")
+	}
+	return nil
+}
+
+func hasLoginForm(doc *goquery.Document) bool {
+	// Check for common login form indicators
+	loginSelectors := []string{
+		"input[type='password']",
+		"form[action*='login']",
+		"form[action*='signin']",
+		"#login-form",
+		".login-form",
+	}
+
+	for _, selector := range loginSelectors {
+		if doc.Find(selector).Length() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
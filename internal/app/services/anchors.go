@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxAnchorPageSize limits how much of a linked page is read to find its
+// anchors
+const maxAnchorPageSize = 5 << 20
+
+// anchorChecker validates link fragments against the id and name attributes
+// of the documents they point to. Other internal pages are fetched at most
+// once per crawl.
+type anchorChecker struct {
+	client  *http.Client
+	page    string
+	anchors map[string]map[string]bool
+}
+
+func newAnchorChecker(doc *goquery.Document, page *url.URL, client *http.Client) *anchorChecker {
+	source := NormalizeLinkURL(page)
+	return &anchorChecker{
+		client:  client,
+		page:    source,
+		anchors: map[string]map[string]bool{source: documentAnchors(doc)},
+	}
+}
+
+// samePage reports whether href points into the crawled page itself
+func (a *anchorChecker) samePage(href string, target *url.URL) bool {
+	return strings.Contains(href, "#") && NormalizeLinkURL(target) == a.page
+}
+
+// valid reports whether the fragment of target names an anchor of its
+// document. Fragments that do not address an element, and documents that
+// cannot be fetched or are not HTML, are given the benefit of the doubt.
+func (a *anchorChecker) valid(ctx context.Context, target *url.URL) bool {
+	fragment := target.Fragment
+	// Text fragments (#:~:text=) may follow an element id
+	if i := strings.Index(fragment, ":~:"); i >= 0 {
+		fragment = fragment[:i]
+	}
+	// "#top" scrolls to the top of the document; "#!" and "#/" are client
+	// side routes
+	if fragment == "" || strings.EqualFold(fragment, "top") ||
+		strings.HasPrefix(fragment, "!") || strings.HasPrefix(fragment, "/") {
+		return true
+	}
+
+	key := NormalizeLinkURL(target)
+	anchors, ok := a.anchors[key]
+	if !ok {
+		anchors = fetchAnchors(ctx, a.client, key)
+		a.anchors[key] = anchors
+	}
+	return anchors == nil || anchors[fragment]
+}
+
+// documentAnchors returns the ids of the elements of a document and the
+// names of its <a name> anchors
+func documentAnchors(doc *goquery.Document) map[string]bool {
+	anchors := make(map[string]bool)
+	doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		anchors[s.AttrOr("id", "")] = true
+	})
+	doc.Find("a[name]").Each(func(i int, s *goquery.Selection) {
+		anchors[s.AttrOr("name", "")] = true
+	})
+	return anchors
+}
+
+// fetchAnchors fetches a page and returns its anchors, or nil when it is not
+// an HTML document or cannot be fetched
+func fetchAnchors(ctx context.Context, client *http.Client, rawURL string) map[string]bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(io.LimitReader(resp.Body, maxAnchorPageSize))
+	if err != nil {
+		return nil
+	}
+	return documentAnchors(doc)
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"gorm.io/gorm"
+)
+
+// ErrEmptyBatch is returned when a batch submission has no valid URL
+var ErrEmptyBatch = errors.New("no valid URLs in batch")
+
+// BatchTooLargeError is returned when a batch has more URLs than allowed
+type BatchTooLargeError struct {
+	Max int
+}
+
+func (e *BatchTooLargeError) Error() string {
+	return fmt.Sprintf("batch exceeds the limit of %d URLs", e.Max)
+}
+
+// BatchRunner stores bulk crawl submissions and crawls their URLs in the
+// background, with at most concurrency crawls of a batch running at a time
+type BatchRunner struct {
+	db          *gorm.DB
+	crawler     *Crawler
+	concurrency int
+	maxURLs     int
+
+	mu  sync.Mutex
+	ctx context.Context
+	wg  sync.WaitGroup
+}
+
+// NewBatchRunner creates a batch runner. maxURLs of 0 allows any batch size.
+func NewBatchRunner(db *gorm.DB, crawler *Crawler, concurrency, maxURLs int) *BatchRunner {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &BatchRunner{
+		db:          db,
+		crawler:     crawler,
+		concurrency: concurrency,
+		maxURLs:     maxURLs,
+	}
+}
+
+// Start resumes the batches left unfinished by a previous process. Batches
+// submitted later run until ctx is cancelled.
+func (r *BatchRunner) Start(ctx context.Context) {
+	r.mu.Lock()
+	r.ctx = ctx
+	r.mu.Unlock()
+
+	var batches []models.Batch
+	if err := r.db.Where("status = ?", models.BatchStatusRunning).Find(&batches).Error; err != nil {
+		logger.Error("Failed to load unfinished batches: %v", err)
+		return
+	}
+
+	for i := range batches {
+		batch := batches[i]
+		// Crawls that were running when the process stopped are lost
+		var interrupted []models.BatchItem
+		r.db.Where("batch_id = ? AND status = ?", batch.ID, models.BatchItemRunning).Find(&interrupted)
+		for j := range interrupted {
+			r.finishItem(&batch, &interrupted[j], errors.New("crawl interrupted by a restart"))
+		}
+		r.launch(batch.ID)
+	}
+}
+
+// Wait blocks until the running batches have stopped
+func (r *BatchRunner) Wait() {
+	r.wg.Wait()
+}
+
+// Submit stores a batch for userID and starts crawling its URLs. The URLs
+// must already be validated and deduplicated.
+func (r *BatchRunner) Submit(userID uint64, urls []string) (*models.Batch, error) {
+	if len(urls) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if r.maxURLs > 0 && len(urls) > r.maxURLs {
+		return nil, &BatchTooLargeError{Max: r.maxURLs}
+	}
+
+	batch := models.Batch{
+		UserID: userID,
+		Status: models.BatchStatusRunning,
+		Total:  len(urls),
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&batch).Error; err != nil {
+			return err
+		}
+		items := make([]models.BatchItem, len(urls))
+		for i, u := range urls {
+			items[i] = models.BatchItem{BatchID: batch.ID, URL: u, Status: models.BatchItemPending}
+		}
+		return tx.CreateInBatches(items, 200).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	r.launch(batch.ID)
+	return &batch, nil
+}
+
+// launch crawls the pending items of a batch in the background
+func (r *BatchRunner) launch(batchID uint) {
+	r.mu.Lock()
+	ctx := r.ctx
+	r.mu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx, batchID)
+	}()
+}
+
+func (r *BatchRunner) run(ctx context.Context, batchID uint) {
+	var batch models.Batch
+	if err := r.db.First(&batch, batchID).Error; err != nil {
+		logger.Error("Failed to load batch %d: %v", batchID, err)
+		return
+	}
+
+	var items []models.BatchItem
+	if err := r.db.Where("batch_id = ? AND status = ?", batchID, models.BatchItemPending).
+		Order("id").
+		Find(&items).Error; err != nil {
+		logger.Error("Failed to load items of batch %d: %v", batchID, err)
+		return
+	}
+
+	jobs := make(chan *models.BatchItem)
+	var wg sync.WaitGroup
+	for i := 0; i < r.concurrency && i < len(items); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				r.crawlItem(ctx, &batch, item)
+			}
+		}()
+	}
+
+feed:
+	for i := range items {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- &items[i]:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	r.completeIfDone(batchID)
+}
+
+func (r *BatchRunner) crawlItem(ctx context.Context, batch *models.Batch, item *models.BatchItem) {
+	run, err := r.crawler.Begin(ctx, batch.UserID, item.URL, models.CrawlOptions{})
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down; the item stays pending and is resumed on next start
+			return
+		}
+		r.finishItem(batch, item, err)
+		return
+	}
+
+	item.CrawlID = &run.ID
+	if err := r.db.Model(item).Updates(map[string]interface{}{
+		"status":   models.BatchItemRunning,
+		"crawl_id": run.ID,
+	}).Error; err != nil {
+		logger.Error("Failed to update batch item %d: %v", item.ID, err)
+	}
+
+	_, err = r.crawler.Run(ctx, run)
+	if err != nil && ctx.Err() != nil {
+		// Shutting down; the item is resumed as interrupted on next start
+		return
+	}
+	r.finishItem(batch, item, err)
+}
+
+// finishItem records the outcome of an item and updates the batch counters
+func (r *BatchRunner) finishItem(batch *models.Batch, item *models.BatchItem, crawlErr error) {
+	status, counter := models.BatchItemCompleted, "completed"
+	errMessage := ""
+	if crawlErr != nil {
+		status, counter = models.BatchItemFailed, "failed"
+		errMessage = crawlErr.Error()
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(item).Updates(map[string]interface{}{
+			"status": status,
+			"error":  errMessage,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Batch{}).Where("id = ?", batch.ID).
+			Update(counter, gorm.Expr(counter+" + 1")).Error
+	})
+	if err != nil {
+		logger.Error("Failed to record batch item %d: %v", item.ID, err)
+	}
+}
+
+// completeIfDone marks a batch as completed once all its items have finished
+func (r *BatchRunner) completeIfDone(batchID uint) {
+	var remaining int64
+	if err := r.db.Model(&models.BatchItem{}).
+		Where("batch_id = ? AND status IN ?", batchID, []string{models.BatchItemPending, models.BatchItemRunning}).
+		Count(&remaining).Error; err != nil || remaining > 0 {
+		return
+	}
+
+	now := time.Now()
+	if err := r.db.Model(&models.Batch{}).Where("id = ?", batchID).Updates(map[string]interface{}{
+		"status":      models.BatchStatusCompleted,
+		"finished_at": now,
+	}).Error; err != nil {
+		logger.Error("Failed to complete batch %d: %v", batchID, err)
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// ParseURLLines reads newline-separated URLs. Blank lines and lines starting
+// with # are skipped.
+func ParseURLLines(r io.Reader) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read URL list: %w", err)
+	}
+	return urls, nil
+}
+
+// ParseURLCSV reads the URLs of a CSV file. The URL column is found by its
+// header (column, or "url" when column is empty, case-insensitive). A file
+// with a single column and no matching header is read as a plain URL list.
+func ParseURLCSV(r io.Reader, column string) ([]string, error) {
+	if column == "" {
+		column = "url"
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("CSV file is empty")
+	}
+
+	index := -1
+	for i, name := range records[0] {
+		// Spreadsheet exports often start with a UTF-8 byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			index = i
+			break
+		}
+	}
+	start := 1
+	if index < 0 {
+		if len(records[0]) != 1 {
+			return nil, fmt.Errorf("CSV has no %q column", column)
+		}
+		index, start = 0, 0
+	}
+
+	var urls []string
+	for _, record := range records[start:] {
+		if index >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(record[index], "\ufeff"))
+		if value == "" || (start == 0 && strings.EqualFold(value, column)) {
+			continue
+		}
+		urls = append(urls, value)
+	}
+	return urls, nil
+}
+
+// CleanBatchURLs validates and deduplicates the URLs of a batch submission,
+// keeping the first occurrence of each URL in order. URLs are duplicates when
+// canonicalizer gives them the same canonical form. Only absolute http and
+// https URLs are accepted.
+func CleanBatchURLs(values []string, canonicalizer *URLCanonicalizer) (urls []string, rejected []models.RejectedURL, duplicates int) {
+	seen := make(map[string]struct{}, len(values))
+	rejected = make([]models.RejectedURL, 0)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := url.ParseRequestURI(value)
+		if err != nil {
+			rejected = append(rejected, models.RejectedURL{Value: value, Reason: "invalid URL"})
+			continue
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			rejected = append(rejected, models.RejectedURL{Value: value, Reason: "only http and https URLs are supported"})
+			continue
+		}
+		if parsed.Host == "" {
+			rejected = append(rejected, models.RejectedURL{Value: value, Reason: "URL has no host"})
+			continue
+		}
+
+		canonical, err := canonicalizer.Canonicalize(value)
+		if err != nil {
+			rejected = append(rejected, models.RejectedURL{Value: value, Reason: "invalid URL"})
+			continue
+		}
+		if _, ok := seen[canonical]; ok {
+			duplicates++
+			continue
+		}
+		seen[canonical] = struct{}{}
+		urls = append(urls, value)
+	}
+	return urls, rejected, duplicates
+}
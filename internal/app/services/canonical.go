@@ -0,0 +1,314 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"golang.org/x/net/idna"
+	"gorm.io/gorm"
+)
+
+// trackedURLIndex is the unique index on the canonical URL of each user's
+// tracked URLs
+const trackedURLIndex = "idx_tracked_urls_user_url"
+
+// URLCanonicalizer turns URLs into the canonical form used to store and
+// look up crawls: lower case scheme and host, IDNs in punycode, no default
+// port, no dot segments and no fragment, optionally without a trailing
+// slash and without tracking parameters
+type URLCanonicalizer struct {
+	stripTrailingSlash bool
+	trackingParams     []string
+}
+
+// NewURLCanonicalizer creates a canonicalizer. trackingParams lists the
+// query parameters to strip; nil keeps every parameter.
+func NewURLCanonicalizer(stripTrailingSlash bool, trackingParams []string) *URLCanonicalizer {
+	params := make([]string, 0, len(trackingParams))
+	for _, p := range trackingParams {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			params = append(params, p)
+		}
+	}
+	return &URLCanonicalizer{stripTrailingSlash: stripTrailingSlash, trackingParams: params}
+}
+
+// Canonicalize returns the canonical form of an absolute URL, or
+// ErrInvalidURL
+func (c *URLCanonicalizer) Canonicalize(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Scheme == "" || u.Host == "" || u.Opaque != "" {
+		return "", ErrInvalidURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	host := strings.ToLower(u.Hostname())
+	if !isASCII(host) {
+		if host, err = idna.Lookup.ToASCII(host); err != nil {
+			return "", ErrInvalidURL
+		}
+	}
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	path := removeDotSegments(u.EscapedPath())
+	if path == "" {
+		path = "/"
+	}
+	if c.stripTrailingSlash && path != "/" {
+		path = strings.TrimRight(path, "/")
+		if path == "" {
+			path = "/"
+		}
+	}
+	if u.Path, err = url.PathUnescape(path); err != nil {
+		return "", ErrInvalidURL
+	}
+	u.RawPath = path
+
+	u.RawQuery = c.stripTrackingParams(u.RawQuery)
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), nil
+}
+
+// LinkKey returns the form of a link target used to compare links across
+// pages and crawls: its canonical form, or its normalized form when it has
+// no canonical one
+func (c *URLCanonicalizer) LinkKey(u *url.URL) string {
+	if canonical, err := c.Canonicalize(u.String()); err == nil {
+		return canonical
+	}
+	return NormalizeLinkURL(u)
+}
+
+// stripTrackingParams removes tracking parameters from a raw query, keeping
+// the order and encoding of the others
+func (c *URLCanonicalizer) stripTrackingParams(rawQuery string) string {
+	if len(c.trackingParams) == 0 || rawQuery == "" {
+		return rawQuery
+	}
+
+	kept := make([]string, 0)
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && c.isTrackingParam(strings.ToLower(name)) {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+	return strings.Join(kept, "&")
+}
+
+func (c *URLCanonicalizer) isTrackingParam(name string) bool {
+	for _, p := range c.trackingParams {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// removeDotSegments resolves the "." and ".." segments of a path as
+// described in RFC 3986, section 5.2.4
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	out := make([]string, 0)
+	input := path
+	for input != "" {
+		switch {
+		case strings.HasPrefix(input, "../"):
+			input = input[3:]
+		case strings.HasPrefix(input, "./"):
+			input = input[2:]
+		case strings.HasPrefix(input, "/./"):
+			input = input[2:]
+		case input == "/.":
+			input = "/"
+		case strings.HasPrefix(input, "/../"):
+			input = input[3:]
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case input == "/..":
+			input = "/"
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case input == "." || input == "..":
+			input = ""
+		default:
+			// Move the first segment, with its leading slash, to the output
+			i := strings.Index(input[1:], "/")
+			if i < 0 {
+				out = append(out, input)
+				input = ""
+			} else {
+				out = append(out, input[:i+1])
+				input = input[i+1:]
+			}
+		}
+	}
+	return strings.Join(out, "")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// hashURL returns the SHA-256 of a URL, used to index URLs too long for a
+// MySQL index
+func hashURL(u string) string {
+	sum := sha256.Sum256([]byte(u))
+	return hex.EncodeToString(sum[:])
+}
+
+// CanonicalizeTrackedURLs brings the stored tracked URLs in line with the
+// canonicalizer: every URL is rewritten to its canonical form along with the
+// URL of its runs and schedules, tracked URLs that turn out to be the same
+// page are merged into the oldest one, and the
+// unique index on (user_id, url_hash) is created if it is missing. It runs
+// at startup after the migrations, so changing the canonicalization settings
+// merges the URLs they make equal.
+func CanonicalizeTrackedURLs(db *gorm.DB, canonicalizer *URLCanonicalizer) error {
+	var tracked []models.TrackedURL
+	if err := db.Select("id", "user_id", "url", "url_hash").Order("id").Find(&tracked).Error; err != nil {
+		return fmt.Errorf("failed to load tracked URLs: %w", err)
+	}
+
+	type key struct {
+		userID uint64
+		hash   string
+	}
+	type group struct {
+		canonical string
+		members   []models.TrackedURL
+	}
+	groups := make(map[key]*group, len(tracked))
+	order := make([]key, 0, len(tracked))
+	for _, t := range tracked {
+		canonical, err := canonicalizer.Canonicalize(t.URL)
+		if err != nil {
+			// Keep URLs that cannot be parsed (such as uploads) as they are
+			canonical = t.URL
+		}
+		k := key{t.UserID, hashURL(canonical)}
+		if _, ok := groups[k]; !ok {
+			groups[k] = &group{canonical: canonical}
+			order = append(order, k)
+		}
+		groups[k].members = append(groups[k].members, t)
+	}
+
+	// Duplicates are merged and changed hashes moved out of the way before
+	// any survivor is rewritten, so the unique index never sees two rows
+	// with the same hash
+	merged := 0
+	var touched []key
+	for _, k := range order {
+		g := groups[k]
+		survivor := g.members[0]
+		for _, duplicate := range g.members[1:] {
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				return mergeTrackedURL(tx, duplicate.ID, survivor.ID)
+			}); err != nil {
+				return fmt.Errorf("failed to merge tracked URL %d into %d: %w", duplicate.ID, survivor.ID, err)
+			}
+			merged++
+		}
+		changed := g.canonical != survivor.URL || k.hash != survivor.URLHash
+		if changed {
+			if err := db.Model(&models.TrackedURL{}).Where("id = ?", survivor.ID).
+				Update("url_hash", fmt.Sprintf("pending-%d", survivor.ID)).Error; err != nil {
+				return fmt.Errorf("failed to canonicalize tracked URL %d: %w", survivor.ID, err)
+			}
+		}
+		if changed || len(g.members) > 1 {
+			touched = append(touched, k)
+		}
+	}
+
+	// The runs and schedules of a tracked URL carry its URL too, and runs
+	// are only comparable when their URLs match
+	for _, k := range touched {
+		g := groups[k]
+		id := g.members[0].ID
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.TrackedURL{}).Where("id = ?", id).
+				Updates(map[string]interface{}{"url": g.canonical, "url_hash": k.hash}).Error; err != nil {
+				return err
+			}
+			for _, model := range []interface{}{&models.CrawlResult{}, &models.Schedule{}} {
+				if err := tx.Unscoped().Model(model).
+					Where("tracked_url_id = ? AND url <> ?", id, g.canonical).
+					Update("url", g.canonical).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to canonicalize tracked URL %d: %w", id, err)
+		}
+	}
+	if merged > 0 {
+		logger.Info("Merged %d duplicate tracked URLs", merged)
+	}
+
+	if !db.Migrator().HasIndex(&models.TrackedURL{}, trackedURLIndex) {
+		if err := db.Exec("CREATE UNIQUE INDEX " + trackedURLIndex + " ON tracked_urls (user_id, url_hash)").Error; err != nil {
+			return fmt.Errorf("failed to create tracked URL index: %w", err)
+		}
+	}
+	return nil
+}
+
+// mergeTrackedURL moves the runs, schedules, alert rules and alerts of a
+// duplicate tracked URL to the one it duplicates and deletes it
+func mergeTrackedURL(tx *gorm.DB, duplicateID, survivorID uint) error {
+	for _, model := range []interface{}{
+		&models.CrawlResult{}, &models.Schedule{}, &models.AlertRule{}, &models.Alert{},
+	} {
+		if err := tx.Unscoped().Model(model).
+			Where("tracked_url_id = ?", duplicateID).
+			Update("tracked_url_id", survivorID).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Delete(&models.TrackedURL{}, duplicateID).Error; err != nil {
+		return err
+	}
+	return RefreshTrackedURL(tx, survivorID)
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+func TestCanonicalize(t *testing.T) {
+	plain := NewURLCanonicalizer(false, nil)
+	stripping := NewURLCanonicalizer(true, []string{"utm_*", " FBCLID "})
+
+	tests := []struct {
+		name          string
+		canonicalizer *URLCanonicalizer
+		in            string
+		want          string
+		wantErr       bool
+	}{
+		{name: "case", canonicalizer: plain, in: "HTTPS://Example.COM/Path", want: "https://example.com/Path"},
+		{name: "empty path", canonicalizer: plain, in: "https://example.com", want: "https://example.com/"},
+		{name: "default http port", canonicalizer: plain, in: "http://example.com:80/a", want: "http://example.com/a"},
+		{name: "default https port", canonicalizer: plain, in: "https://example.com:443/a", want: "https://example.com/a"},
+		{name: "other port", canonicalizer: plain, in: "https://example.com:8443/a", want: "https://example.com:8443/a"},
+		{name: "IDN", canonicalizer: plain, in: "https://bücher.example/", want: "https://xn--bcher-kva.example/"},
+		{name: "IPv6", canonicalizer: plain, in: "http://[::1]:80/", want: "http://[::1]/"},
+		{name: "dot segments", canonicalizer: plain, in: "https://example.com/a/./b/../c", want: "https://example.com/a/c"},
+		{name: "dot segments above root", canonicalizer: plain, in: "https://example.com/../a", want: "https://example.com/a"},
+		{name: "fragment", canonicalizer: plain, in: "https://example.com/a#top", want: "https://example.com/a"},
+		{name: "empty query", canonicalizer: plain, in: "https://example.com/a?", want: "https://example.com/a"},
+		{name: "escaped path", canonicalizer: plain, in: "https://example.com/a%2Fb", want: "https://example.com/a%2Fb"},
+		{name: "trailing slash kept", canonicalizer: plain, in: "https://example.com/a/", want: "https://example.com/a/"},
+		{name: "trailing slash stripped", canonicalizer: stripping, in: "https://example.com/a/", want: "https://example.com/a"},
+		{name: "root slash kept", canonicalizer: stripping, in: "https://example.com/", want: "https://example.com/"},
+		{name: "tracking params kept", canonicalizer: plain, in: "https://example.com/?utm_source=x&id=1", want: "https://example.com/?utm_source=x&id=1"},
+		{
+			name:          "tracking params stripped",
+			canonicalizer: stripping,
+			in:            "https://example.com/?UTM_Source=x&id=1&fbclid=y&utm_medium=z",
+			want:          "https://example.com/?id=1",
+		},
+		{name: "relative", canonicalizer: plain, in: "/a", wantErr: true},
+		{name: "no host", canonicalizer: plain, in: "https:///a", wantErr: true},
+		{name: "opaque", canonicalizer: plain, in: "mailto:someone@example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.canonicalizer.Canonicalize(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Canonicalize(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Canonicalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanBatchURLs(t *testing.T) {
+	canonicalizer := NewURLCanonicalizer(true, []string{"utm_*"})
+	values := []string{
+		"https://Example.com",
+		" https://example.com/ ",
+		"https://example.com:443/?utm_source=x",
+		"https://example.com/docs/",
+		"https://example.com/docs",
+		"",
+		"ftp://example.com/",
+		"example.com",
+	}
+
+	urls, rejected, duplicates := CleanBatchURLs(values, canonicalizer)
+	if want := []string{"https://Example.com", "https://example.com/docs/"}; !reflect.DeepEqual(urls, want) {
+		t.Errorf("urls = %q, want %q", urls, want)
+	}
+	if duplicates != 3 {
+		t.Errorf("duplicates = %d, want 3", duplicates)
+	}
+	want := []models.RejectedURL{
+		{Value: "ftp://example.com/", Reason: "only http and https URLs are supported"},
+		{Value: "example.com", Reason: "invalid URL"},
+	}
+	if !reflect.DeepEqual(rejected, want) {
+		t.Errorf("rejected = %+v, want %+v", rejected, want)
+	}
+}
+
+func TestExtractLinkEdgesCanonical(t *testing.T) {
+	canonicalizer := NewURLCanonicalizer(true, []string{"utm_*"})
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body>
+		<a href="/Docs/?utm_source=nav#intro">Docs</a>
+		<a href="HTTPS://EXAMPLE.COM:443/blog/">Blog</a>
+	</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := url.Parse("https://Example.com/index/")
+
+	edges := ExtractLinkEdges(doc, page, page.String(), canonicalizer)
+	want := []string{"https://example.com/Docs", "https://example.com/blog"}
+	if len(edges) != len(want) {
+		t.Fatalf("got %d edges, want %d", len(edges), len(want))
+	}
+	for i, edge := range edges {
+		if edge.SourceURL != "https://example.com/index" {
+			t.Errorf("edge %d source = %q, want https://example.com/index", i, edge.SourceURL)
+		}
+		if edge.TargetURL != want[i] {
+			t.Errorf("edge %d target = %q, want %q", i, edge.TargetURL, want[i])
+		}
+	}
+}
+
+func TestCanonicalizeTrackedURLs(t *testing.T) {
+	db := newTestDB(t,
+		&models.TrackedURL{}, &models.CrawlResult{}, &models.Schedule{}, &models.AlertRule{}, &models.Alert{},
+	)
+
+	// Two spellings of the same page and a page that only needs rewriting
+	urls := []string{"https://example.com/", "HTTPS://Example.com:443", "https://Other.example/a"}
+	trackedIDs := make([]uint, len(urls))
+	for i, u := range urls {
+		tracked := models.TrackedURL{UserID: 1, URL: u, URLHash: hashURL(u)}
+		if err := db.Create(&tracked).Error; err != nil {
+			t.Fatal(err)
+		}
+		trackedIDs[i] = tracked.ID
+		run := models.CrawlResult{UserID: 1, TrackedURLID: tracked.ID, URL: u, Status: models.CrawlStatusCompleted}
+		if err := db.Create(&run).Error; err != nil {
+			t.Fatal(err)
+		}
+		schedule := models.Schedule{UserID: 1, TrackedURLID: tracked.ID, URL: u, IntervalSeconds: 3600, Enabled: true}
+		if err := db.Create(&schedule).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := CanonicalizeTrackedURLs(db, NewURLCanonicalizer(false, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	var tracked []models.TrackedURL
+	if err := db.Order("id").Find(&tracked).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 2 || tracked[0].ID != trackedIDs[0] || tracked[1].ID != trackedIDs[2] {
+		t.Fatalf("tracked URLs = %+v, want %d and %d", tracked, trackedIDs[0], trackedIDs[2])
+	}
+	want := map[uint]string{trackedIDs[0]: "https://example.com/", trackedIDs[2]: "https://other.example/a"}
+	for _, tu := range tracked {
+		if tu.URL != want[tu.ID] || tu.URLHash != hashURL(want[tu.ID]) {
+			t.Errorf("tracked URL %d = %s, want %s", tu.ID, tu.URL, want[tu.ID])
+		}
+	}
+	if tracked[0].RunCount != 2 {
+		t.Errorf("merged tracked URL has %d runs, want 2", tracked[0].RunCount)
+	}
+
+	// Runs and schedules follow their tracked URL, so runs of the same
+	// tracked URL stay comparable
+	var runs []models.CrawlResult
+	if err := db.Find(&runs).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, run := range runs {
+		if run.URL != want[run.TrackedURLID] {
+			t.Errorf("run %d of tracked URL %d has URL %s, want %s", run.ID, run.TrackedURLID, run.URL, want[run.TrackedURLID])
+		}
+	}
+	var schedules []models.Schedule
+	if err := db.Find(&schedules).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, schedule := range schedules {
+		if schedule.URL != want[schedule.TrackedURLID] {
+			t.Errorf("schedule %d of tracked URL %d has URL %s, want %s", schedule.ID, schedule.TrackedURLID, schedule.URL, want[schedule.TrackedURLID])
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	topTermsLimit = 10
+	// minLanguageHits is the number of stopword matches required before a
+	// language is reported; shorter texts are too ambiguous to classify.
+	minLanguageHits = 5
+)
+
+// boilerplateSelector matches elements that never contribute to the main
+// content of a page
+var boilerplateSelector = strings.Join([]string{
+	"script", "style", "noscript", "template", "svg", "iframe", "head",
+	"nav", "footer", "[role='navigation']", "[role='contentinfo']",
+	"[aria-hidden='true']", "[hidden]",
+}, ", ")
+
+// ExtractVisibleText returns the human-readable text of the page body with
+// scripts, styles and navigation/footer boilerplate removed. The document
+// itself is not modified.
+func ExtractVisibleText(doc *goquery.Document) string {
+	root := doc.Find("body")
+	if root.Length() == 0 {
+		root = doc.Selection
+	}
+	body := root.Clone()
+	body.Find(boilerplateSelector).Remove()
+
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			if text := strings.TrimSpace(n.Data); text != "" {
+				if sb.Len() > 0 {
+					sb.WriteByte(' ')
+				}
+				sb.WriteString(text)
+			}
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range body.Nodes {
+		walk(n)
+	}
+
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// AnalyzeContent computes word and sentence statistics, readability, top
+// keywords and n-grams and the language of the visible page text, and
+// compares it against the language declared on the <html> element.
+func AnalyzeContent(doc *goquery.Document) models.ContentAnalysis {
+	text := ExtractVisibleText(doc)
+	words := tokenizeWords(text)
+
+	analysis := models.ContentAnalysis{
+		WordCount:        len(words),
+		SentenceCount:    countSentences(text, len(words)),
+		DeclaredLanguage: declaredLanguage(doc),
+		TopKeywords:      make([]models.TermCount, 0),
+		TopBigrams:       make([]models.TermCount, 0),
+		TopTrigrams:      make([]models.TermCount, 0),
+	}
+
+	if analysis.WordCount == 0 {
+		return analysis
+	}
+
+	analysis.AvgWordsPerSentence = round1(float64(analysis.WordCount) / float64(analysis.SentenceCount))
+
+	syllables := 0
+	for _, w := range words {
+		syllables += countSyllables(w)
+	}
+	analysis.ReadabilityScore = round1(fleschReadingEase(analysis.WordCount, analysis.SentenceCount, syllables))
+	analysis.ReadabilityLevel = readabilityLevel(analysis.ReadabilityScore)
+
+	analysis.DetectedLanguage, analysis.LanguageConfidence = detectLanguage(words)
+
+	stop := stopwords["en"]
+	if set, ok := stopwords[analysis.DetectedLanguage]; ok {
+		stop = set
+	}
+	analysis.TopKeywords = topKeywords(words, stop)
+	analysis.TopBigrams = topNGrams(words, stop, 2)
+	analysis.TopTrigrams = topNGrams(words, stop, 3)
+
+	analysis.LanguageMismatch = analysis.DeclaredLanguage != "" &&
+		analysis.DetectedLanguage != "" &&
+		analysis.DeclaredLanguage != analysis.DetectedLanguage
+
+	return analysis
+}
+
+// tokenizeWords splits text into lowercase words. Apostrophes inside a word
+// are kept so that contractions count as a single word.
+func tokenizeWords(text string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		word := strings.Trim(string(current), "'’")
+		if word != "" {
+			words = append(words, word)
+		}
+		current = current[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			current = append(current, r)
+		case (r == '\'' || r == '’') && len(current) > 0:
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return words
+}
+
+// countSentences counts runs of sentence terminators. Text without any
+// terminator still counts as a single sentence.
+func countSentences(text string, wordCount int) int {
+	if wordCount == 0 {
+		return 0
+	}
+
+	sentences := 0
+	inTerminator := false
+	for _, r := range text {
+		if r == '.' || r == '!' || r == '?' || r == '…' {
+			if !inTerminator {
+				sentences++
+			}
+			inTerminator = true
+			continue
+		}
+		inTerminator = false
+	}
+
+	if sentences == 0 {
+		return 1
+	}
+	return sentences
+}
+
+// countSyllables estimates the syllables in a word by counting vowel groups,
+// ignoring a trailing silent "e".
+func countSyllables(word string) int {
+	word = foldAccents(word)
+	count := 0
+	prevVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	if count == 0 {
+		return 1
+	}
+	return count
+}
+
+// fleschReadingEase computes the Flesch reading-ease score; higher scores
+// indicate text that is easier to read.
+func fleschReadingEase(words, sentences, syllables int) float64 {
+	return 206.835 -
+		1.015*(float64(words)/float64(sentences)) -
+		84.6*(float64(syllables)/float64(words))
+}
+
+func readabilityLevel(score float64) string {
+	switch {
+	case score >= 90:
+		return "very easy"
+	case score >= 80:
+		return "easy"
+	case score >= 70:
+		return "fairly easy"
+	case score >= 60:
+		return "standard"
+	case score >= 50:
+		return "fairly difficult"
+	case score >= 30:
+		return "difficult"
+	default:
+		return "very difficult"
+	}
+}
+
+// detectLanguage picks the language whose stopwords occur most often in the
+// text and returns it together with its share of all stopword hits.
+func detectLanguage(words []string) (string, float64) {
+	hits := make(map[string]int)
+	total := 0
+	for _, w := range words {
+		folded := foldAccents(w)
+		for lang, set := range stopwords {
+			if _, ok := set[folded]; ok {
+				hits[lang]++
+				total++
+			}
+		}
+	}
+
+	best, bestHits := "", 0
+	for lang, n := range hits {
+		if n > bestHits || (n == bestHits && lang < best) {
+			best, bestHits = lang, n
+		}
+	}
+
+	if bestHits < minLanguageHits {
+		return "", 0
+	}
+	return best, round2(float64(bestHits) / float64(total))
+}
+
+// declaredLanguage returns the primary subtag of the <html lang> attribute
+func declaredLanguage(doc *goquery.Document) string {
+	lang := strings.TrimSpace(doc.Find("html").AttrOr("lang", ""))
+	if lang == "" {
+		return ""
+	}
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	return strings.ToLower(lang)
+}
+
+func isKeywordCandidate(word string, stop map[string]struct{}) bool {
+	if len([]rune(word)) < 3 {
+		return false
+	}
+	if _, ok := stop[foldAccents(word)]; ok {
+		return false
+	}
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func topKeywords(words []string, stop map[string]struct{}) []models.TermCount {
+	counts := make(map[string]int)
+	for _, w := range words {
+		if isKeywordCandidate(w, stop) {
+			counts[w]++
+		}
+	}
+	return topTerms(counts, 1)
+}
+
+// topNGrams counts n-grams made up entirely of keyword candidates, so that
+// phrases are not dominated by "of the" and similar function words.
+func topNGrams(words []string, stop map[string]struct{}, n int) []models.TermCount {
+	counts := make(map[string]int)
+	for i := 0; i+n <= len(words); i++ {
+		gram := words[i : i+n]
+		valid := true
+		for _, w := range gram {
+			if !isKeywordCandidate(w, stop) {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			counts[strings.Join(gram, " ")]++
+		}
+	}
+	// A phrase that appears only once is not a meaningful n-gram
+	return topTerms(counts, 2)
+}
+
+func topTerms(counts map[string]int, minCount int) []models.TermCount {
+	terms := make([]models.TermCount, 0, len(counts))
+	for term, count := range counts {
+		if count >= minCount {
+			terms = append(terms, models.TermCount{Term: term, Count: count})
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Count != terms[j].Count {
+			return terms[i].Count > terms[j].Count
+		}
+		return terms[i].Term < terms[j].Term
+	})
+	if len(terms) > topTermsLimit {
+		terms = terms[:topTermsLimit]
+	}
+	return terms
+}
+
+// foldAccents strips combining marks so that "está" matches "esta"
+func foldAccents(s string) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// maxCookieSize is the largest name and value size browsers are required
+// to accept
+const maxCookieSize = 4096
+
+// sessionCookieName matches the names of cookies that look like they hold a
+// session or credentials
+var sessionCookieName = regexp.MustCompile(`(?i)(sess|sid$|^sid|auth|token|jwt|login|remember|credential)`)
+
+// csrfCookieName matches the names of anti-CSRF token cookies, which scripts
+// must be able to read and so are not expected to be HttpOnly
+var csrfCookieName = regexp.MustCompile(`(?i)(csrf|xsrf)`)
+
+// AnalyzeCookies records every cookie set by the given responses, the page
+// fetch and the redirects that led to it, and flags insecure ones
+func AnalyzeCookies(responses []*http.Response) models.CookieReport {
+	report := models.CookieReport{
+		Summary: models.CookieSummary{ByIssue: make(map[string]int)},
+		Cookies: make([]models.ResponseCookie, 0),
+	}
+
+	for _, resp := range responses {
+		setBy := ""
+		overHTTP := false
+		if resp.Request != nil && resp.Request.URL != nil {
+			setBy = resp.Request.URL.String()
+			overHTTP = resp.Request.URL.Scheme == "http"
+		}
+
+		for _, c := range resp.Cookies() {
+			cookie := models.ResponseCookie{
+				Name:     c.Name,
+				Domain:   c.Domain,
+				Path:     c.Path,
+				Secure:   c.Secure,
+				HttpOnly: c.HttpOnly,
+				SameSite: sameSiteName(c.SameSite),
+				Size:     len(c.Name) + len(c.Value),
+				SetBy:    setBy,
+				Issues:   make([]string, 0),
+			}
+			if !c.Expires.IsZero() {
+				expires := c.Expires.UTC()
+				cookie.Expires = &expires
+			}
+			// Cookie.MaxAge is 0 when unset and negative for Max-Age=0
+			switch {
+			case c.MaxAge > 0:
+				maxAge := c.MaxAge
+				cookie.MaxAge = &maxAge
+			case c.MaxAge < 0:
+				maxAge := 0
+				cookie.MaxAge = &maxAge
+			}
+			cookie.Session = cookie.Expires == nil && cookie.MaxAge == nil
+
+			if strings.EqualFold(cookie.SameSite, "None") && !c.Secure {
+				cookie.Issues = append(cookie.Issues, models.CookieSameSiteNoneInsecure)
+			}
+			if isSessionCookie(c.Name) && !c.HttpOnly {
+				cookie.Issues = append(cookie.Issues, models.CookieSessionNotHTTPOnly)
+			}
+			if overHTTP {
+				cookie.Issues = append(cookie.Issues, models.CookieSetOverHTTP)
+			}
+			if !validCookiePrefix(c) {
+				cookie.Issues = append(cookie.Issues, models.CookieInvalidPrefix)
+			}
+			if cookie.Size > maxCookieSize {
+				cookie.Issues = append(cookie.Issues, models.CookieOversized)
+			}
+
+			for _, issue := range cookie.Issues {
+				report.Summary.ByIssue[issue]++
+			}
+			if len(cookie.Issues) > 0 {
+				report.Summary.Insecure++
+			}
+			report.Cookies = append(report.Cookies, cookie)
+		}
+	}
+
+	report.Summary.Total = len(report.Cookies)
+	return report
+}
+
+// isSessionCookie reports whether a cookie name looks like it holds a
+// session or credentials
+func isSessionCookie(name string) bool {
+	return sessionCookieName.MatchString(name) && !csrfCookieName.MatchString(name)
+}
+
+// sameSiteName returns the SameSite attribute of a cookie as sent, or ""
+// when it is absent
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	case http.SameSiteDefaultMode:
+		return "Default"
+	}
+	return ""
+}
+
+// validCookiePrefix checks the requirements of the __Secure- and __Host-
+// cookie name prefixes
+func validCookiePrefix(c *http.Cookie) bool {
+	switch {
+	case strings.HasPrefix(c.Name, "__Host-"):
+		return c.Secure && c.Domain == "" && c.Path == "/"
+	case strings.HasPrefix(c.Name, "__Secure-"):
+		return c.Secure
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package services
+
+import "testing"
+
+func TestIsSessionCookie(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "PHPSESSID", want: true},
+		{name: "connect.sid", want: true},
+		{name: "sid", want: true},
+		{name: "auth_token", want: true},
+		{name: "jwt", want: true},
+		{name: "remember_me", want: true},
+		{name: "XSRF-TOKEN", want: false},
+		{name: "csrftoken", want: false},
+		{name: "_csrf", want: false},
+		{name: "theme", want: false},
+		{name: "_ga", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSessionCookie(tt.name); got != tt.want {
+				t.Errorf("isSessionCookie(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"gorm.io/gorm"
+)
+
+// fetchTimeout bounds how long fetching the crawled page may take
+const fetchTimeout = 30 * time.Second
+
+// maxRedirects is the number of redirects followed when fetching a page
+const maxRedirects = 10
+
+// uploadScheme is the URL scheme of uploads analyzed without a base URL
+const uploadScheme = "upload"
+
+// ErrInvalidURL is returned when the URL to crawl cannot be parsed
+var ErrInvalidURL = errors.New("invalid URL")
+
+// FetchError is returned when the page to crawl cannot be fetched
+type FetchError struct {
+	Err error
+}
+
+func (e *FetchError) Error() string {
+	return "failed to fetch URL: " + e.Err.Error()
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// CrawlOutput is the stored crawl run together with the raw HTML it was
+// computed from
+type CrawlOutput struct {
+	Result *models.CrawlResult
+	HTML   []byte
+}
+
+// Crawler fetches and analyzes pages and stores every crawl as a new run of
+// the user's tracked URL
+type Crawler struct {
+	db        *gorm.DB
+	snapshots *SnapshotService
+	webhooks  *WebhookDispatcher
+	progress  *ProgressHub
+	links     *LinkStatusCache
+	urls      *URLCanonicalizer
+	techs     *Fingerprinter
+	client    *http.Client
+	runLimit  int
+}
+
+// NewCrawler creates a crawler. runLimit is the number of unpinned runs kept
+// per tracked URL, 0 keeps every run. webhooks and progress may be nil to
+// publish no events, links may be nil to check every link on every crawl.
+// Requests to crawled sites go through hosts, crawled URLs are stored in
+// the canonical form of urls and techs detects what pages are built with.
+func NewCrawler(db *gorm.DB, snapshots *SnapshotService, webhooks *WebhookDispatcher, progress *ProgressHub, links *LinkStatusCache, hosts *HostScheduler, urls *URLCanonicalizer, techs *Fingerprinter, runLimit int) *Crawler {
+	return &Crawler{
+		db:        db,
+		snapshots: snapshots,
+		webhooks:  webhooks,
+		progress:  progress,
+		links:     links,
+		urls:      urls,
+		techs:     techs,
+		client:    hosts.Client(fetchTimeout),
+		runLimit:  runLimit,
+	}
+}
+
+// Crawl fetches rawURL, analyzes the page and stores the result for userID.
+// It is Begin followed by Run.
+func (cr *Crawler) Crawl(ctx context.Context, userID uint64, rawURL string, opts models.CrawlOptions) (*CrawlOutput, error) {
+	run, err := cr.Begin(ctx, userID, rawURL, opts)
+	if err != nil {
+		cr.webhooks.Publish(userID, models.WebhookEventCrawlFailed, models.CrawlFailedEventData{
+			URL:   rawURL,
+			Error: err.Error(),
+		})
+		return nil, err
+	}
+	return cr.Run(ctx, run)
+}
+
+// Begin validates rawURL and stores a new running run for its canonical
+// form, so the crawl has an ID that its progress can be followed by before
+// it has finished
+func (cr *Crawler) Begin(ctx context.Context, userID uint64, rawURL string, opts models.CrawlOptions) (*models.CrawlResult, error) {
+	canonicalURL, err := cr.urls.Canonicalize(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return cr.begin(ctx, &models.CrawlResult{
+		URL:             canonicalURL,
+		UserID:          userID,
+		Status:          models.CrawlStatusRunning,
+		SourceType:      models.SourceTypeCrawl,
+		FreshLinkChecks: opts.FreshLinkChecks,
+	})
+}
+
+// begin stores a new run under the tracked URL of its URL
+func (cr *Crawler) begin(ctx context.Context, run *models.CrawlResult) (*models.CrawlResult, error) {
+	userID, rawURL := run.UserID, run.URL
+	err := cr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tracked, err := FindOrCreateTrackedURL(tx, userID, rawURL)
+		if err != nil {
+			return err
+		}
+
+		run.TrackedURLID = tracked.ID
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		return RefreshTrackedURL(tx, tracked.ID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save crawl result: %w", err)
+	}
+	return run, nil
+}
+
+// Run performs a crawl stored by Begin and completes or fails its run. The
+// outcome is published to the crawl's progress stream and the user's
+// webhooks.
+func (cr *Crawler) Run(ctx context.Context, run *models.CrawlResult) (*CrawlOutput, error) {
+	output, err := cr.crawl(WithHostUser(ctx, run.UserID), run)
+	if err != nil {
+		cr.Fail(run, err)
+		return nil, err
+	}
+	cr.complete(output.Result)
+	return output, nil
+}
+
+// AnalyzeUpload analyzes uploaded HTML without fetching anything but its
+// links, and stores the result for userID as a run with source type upload.
+// See BeginUpload for how the run's URL is chosen.
+func (cr *Crawler) AnalyzeUpload(ctx context.Context, userID uint64, body []byte, baseURL, name string) (*CrawlOutput, error) {
+	run, err := cr.BeginUpload(ctx, userID, body, baseURL, name)
+	if err != nil {
+		return nil, err
+	}
+	return cr.RunUpload(ctx, run, body)
+}
+
+// BeginUpload validates baseURL and stores a new running upload run for
+// userID, to be analyzed by RunUpload. Relative links resolve against
+// baseURL, which must be an absolute HTTP(S) URL. Without one the run is
+// stored under an upload: URL named after the content hash and name, so
+// only uploads of the same document share a run history, and only absolute
+// links are checked.
+func (cr *Crawler) BeginUpload(ctx context.Context, userID uint64, body []byte, baseURL, name string) (*models.CrawlResult, error) {
+	sum := sha256.Sum256(body)
+	pageURL := (&url.URL{Scheme: uploadScheme, Path: "/" + hex.EncodeToString(sum[:8]) + "/" + name}).String()
+	if baseURL != "" {
+		canonicalURL, err := cr.urls.Canonicalize(baseURL)
+		if err != nil || (!strings.HasPrefix(canonicalURL, "http://") && !strings.HasPrefix(canonicalURL, "https://")) {
+			return nil, ErrInvalidURL
+		}
+		pageURL = canonicalURL
+	}
+
+	return cr.begin(ctx, &models.CrawlResult{
+		URL:        pageURL,
+		UserID:     userID,
+		Status:     models.CrawlStatusRunning,
+		SourceType: models.SourceTypeUpload,
+	})
+}
+
+// RunUpload analyzes the uploaded HTML of a run stored by BeginUpload and
+// completes or fails the run like Run
+func (cr *Crawler) RunUpload(ctx context.Context, run *models.CrawlResult, body []byte) (*CrawlOutput, error) {
+	parsedURL, err := url.Parse(run.URL)
+	if err != nil {
+		cr.Fail(run, err)
+		return nil, err
+	}
+	output, err := cr.analyze(WithHostUser(ctx, run.UserID), run, body, nil, parsedURL)
+	if err != nil {
+		cr.Fail(run, err)
+		return nil, err
+	}
+	cr.complete(output.Result)
+	return output, nil
+}
+
+// complete publishes the outcome of a completed run to its progress stream
+// and the user's webhooks
+func (cr *Crawler) complete(result *models.CrawlResult) {
+	summary := models.CrawlEventData{
+		CrawlID:           result.ID,
+		TrackedURLID:      result.TrackedURLID,
+		URL:               result.URL,
+		PageTitle:         result.PageTitle,
+		HTMLVersion:       result.HTMLVersion,
+		InternalLinks:     result.InternalLinks,
+		ExternalLinks:     result.ExternalLinks,
+		InaccessibleLinks: len(result.InaccessibleLinks),
+		HasLoginForm:      result.HasLoginForm,
+	}
+	cr.progress.Publish(result.ID, models.ProgressCompleted, summary)
+	cr.webhooks.Publish(result.UserID, models.WebhookEventCrawlCompleted, summary)
+	if len(result.InaccessibleLinks) > 0 {
+		cr.webhooks.Publish(result.UserID, models.WebhookEventLinkBroken, models.LinkBrokenEventData{
+			CrawlID:      result.ID,
+			TrackedURLID: result.TrackedURLID,
+			URL:          result.URL,
+			Links:        result.InaccessibleLinks,
+		})
+	}
+}
+
+func (cr *Crawler) crawl(ctx context.Context, result *models.CrawlResult) (*CrawlOutput, error) {
+	rawURL := result.URL
+	parsedURL, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+
+	// Fetch the page
+	cr.progress.Publish(result.ID, models.ProgressFetchStarted, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return nil, &FetchError{Err: err}
+	}
+	// Keep the redirect responses for the cookies they set
+	var redirects []*http.Response
+	client := *cr.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		redirects = append(redirects, req.Response)
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &FetchError{Err: err}
+	}
+	defer resp.Body.Close()
+	result.Cookies = AnalyzeCookies(append(redirects, resp))
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	cr.progress.Publish(result.ID, models.ProgressPageFetched, models.PageFetchedData{
+		StatusCode:  resp.StatusCode,
+		Bytes:       len(body),
+		ContentType: resp.Header.Get("Content-Type"),
+	})
+
+	// Relative links resolve against the address the page was served from,
+	// which differs from the requested one after redirects
+	pageURL := parsedURL
+	if resp.Request != nil && resp.Request.URL != nil {
+		pageURL = resp.Request.URL
+	}
+
+	return cr.analyze(ctx, result, body, resp.Header, pageURL)
+}
+
+// analyze analyzes the HTML of a page served from pageURL, checks its links
+// and completes its run
+func (cr *Crawler) analyze(ctx context.Context, result *models.CrawlResult, body []byte, header http.Header, pageURL *url.URL) (*CrawlOutput, error) {
+	rawURL := result.URL
+
+	// Parse the HTML
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	// Extract data
+	result.Doctype = AnalyzeDoctype(body)
+	result.HTMLVersion = result.Doctype.Version
+	result.Markup = AnalyzeMarkup(body)
+	result.PageTitle = doc.Find("title").Text()
+
+	// Count headings
+	result.Headings = models.HeadingCounts{
+		H1: doc.Find("h1").Length(),
+		H2: doc.Find("h2").Length(),
+		H3: doc.Find("h3").Length(),
+		H4: doc.Find("h4").Length(),
+		H5: doc.Find("h5").Length(),
+		H6: doc.Find("h6").Length(),
+	}
+	result.Outline = BuildHeadingOutline(doc)
+
+	// Count links and get broken links
+	cr.progress.Publish(result.ID, models.ProgressLinksDiscovered, models.LinksDiscoveredData{
+		Total: doc.Find("a[href]").FilterFunction(func(i int, s *goquery.Selection) bool {
+			href, _ := s.Attr("href")
+			return href != "" && !strings.HasPrefix(href, "#")
+		}).Length(),
+	})
+	links := countLinks(ctx, doc, pageURL, cr.client, cr.links, result.FreshLinkChecks, func(status models.LinkStatus) {
+		cr.progress.Publish(result.ID, models.ProgressLinkChecked, models.LinkCheckedData{
+			URL:        status.URL,
+			StatusCode: status.StatusCode,
+			Accessible: status.Accessible(),
+			Error:      status.Error,
+			Cached:     status.Cached,
+		})
+	})
+	result.InternalLinks = links.internal
+	result.ExternalLinks = links.external
+	result.InaccessibleLinks = links.broken
+	result.Links = links.analysis
+
+	// Keep the links themselves for the site link graph, under the URL the
+	// graph knows the page by
+	edges := ExtractLinkEdges(doc, pageURL, result.URL, cr.urls)
+
+	// Check for login form and inventory every form
+	result.HasLoginForm = hasLoginForm(doc)
+	result.Forms = AnalyzeForms(doc, pageURL)
+
+	// Detect the technologies the site is built with
+	result.Technologies = cr.techs.Detect(header, doc, body)
+	result.TechnologyNames = technologyNames(result.Technologies)
+
+	// Inventory the third parties the page loads
+	result.ThirdParties = AnalyzeThirdParties(doc, pageURL)
+	result.Metadata = ExtractMetadata(doc, header)
+
+	// Analyze the visible text content and fingerprint it for near-duplicate detection
+	result.Content = AnalyzeContent(doc)
+	result.ContentFingerprint = ContentFingerprint(doc)
+	result.WordCount = result.Content.WordCount
+	result.ReadabilityScore = result.Content.ReadabilityScore
+	result.Language = result.Content.DetectedLanguage
+	result.LanguageMismatch = result.Content.LanguageMismatch
+	cr.progress.Publish(result.ID, models.ProgressAnalysisFinished, nil)
+
+	// Store a compressed snapshot of the fetched HTML
+	if hash, err := cr.snapshots.Save(ctx, result.UserID, body); err != nil {
+		logger.Warn("Snapshot not stored for %s: %v", rawURL, err)
+	} else {
+		result.SnapshotHash = hash
+	}
+
+	// Score the page with the user's default scoring profile
+	profile, err := DefaultScoringProfile(cr.db.WithContext(ctx), result.UserID)
+	if err != nil {
+		logger.Warn("Default scoring rules used for %s: %v", rawURL, err)
+	}
+	result.ScoreBreakdown = ComputeScore(result, profile)
+	result.HealthScore = &result.ScoreBreakdown.Score
+
+	result.Status = models.CrawlStatusCompleted
+	if err := cr.finishRun(ctx, result, edges); err != nil {
+		return nil, err
+	}
+
+	// Compare with the previous run; a failure here must not fail the crawl
+	alerts, err := EvaluateAlertRules(cr.db.WithContext(ctx), result)
+	if err != nil {
+		logger.Error("Alert rules not evaluated for %s: %v", rawURL, err)
+	}
+	for _, alert := range alerts {
+		cr.webhooks.Publish(result.UserID, models.WebhookEventAlertTriggered, alert)
+	}
+
+	return &CrawlOutput{Result: result, HTML: body}, nil
+}
+
+// finishRun stores the outcome of a run with its link edges and prunes the
+// old runs of its URL
+func (cr *Crawler) finishRun(ctx context.Context, result *models.CrawlResult, edges []models.LinkEdge) error {
+	err := cr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(result).Error; err != nil {
+			return err
+		}
+		for i := range edges {
+			edges[i].CrawlID = result.ID
+			edges[i].UserID = result.UserID
+		}
+		if len(edges) > 0 {
+			if err := tx.CreateInBatches(edges, 500).Error; err != nil {
+				return err
+			}
+		}
+		if err := PruneRuns(tx, result.TrackedURLID, cr.runLimit); err != nil {
+			return err
+		}
+		return RefreshTrackedURL(tx, result.TrackedURLID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save crawl result: %w", err)
+	}
+	return nil
+}
+
+// Fail marks a run stored by Begin as failed, e.g. when it could not be
+// queued, and publishes the failure. It does not use the crawl's context,
+// which may be the reason the crawl failed.
+func (cr *Crawler) Fail(run *models.CrawlResult, cause error) {
+	run.Status = models.CrawlStatusFailed
+	run.Error = cause.Error()
+	if err := cr.db.Model(run).Select("status", "error").Updates(run).Error; err != nil {
+		logger.Error("Failed to mark crawl %d as failed: %v", run.ID, err)
+	} else if err := PruneRuns(cr.db, run.TrackedURLID, cr.runLimit); err != nil {
+		logger.Error("Failed to prune runs of %s: %v", run.URL, err)
+	}
+
+	failure := models.CrawlFailedEventData{URL: run.URL, Error: cause.Error()}
+	cr.progress.Publish(run.ID, models.ProgressFailed, failure)
+	cr.webhooks.Publish(run.UserID, models.WebhookEventCrawlFailed, failure)
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/storage"
+)
+
+// newTestCrawler returns a crawler backed by a test database that checks
+// every link on every crawl
+func newTestCrawler(t *testing.T) *Crawler {
+	t.Helper()
+	db := newTestDB(t,
+		&models.User{}, &models.TrackedURL{}, &models.CrawlResult{}, &models.Snapshot{},
+		&models.AlertRule{}, &models.Alert{}, &models.LinkEdge{}, &models.ScoringProfile{},
+	)
+	techs, err := LoadFingerprinter("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCrawler(
+		db,
+		NewSnapshotService(db, storage.NewLocalStore(t.TempDir()), 0),
+		nil,
+		nil,
+		nil,
+		NewHostScheduler(0, 0, 4, false),
+		NewURLCanonicalizer(false, nil),
+		techs,
+		0,
+	)
+}
+
+func TestCrawlResolvesLinksAfterRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/docs/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/docs/" {
+			w.Write([]byte("<html><body>page</body></html>"))
+			return
+		}
+		w.Write([]byte(`<!DOCTYPE html><html><head><title>Docs</title></head><body>
+			<a href="intro">Intro</a>
+			<a href="./setup#install">Setup</a>
+			<a href="../about">About</a>
+			<form action="search"><input name="q"></form>
+		</body></html>`))
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>about</body></html>"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	crawler := newTestCrawler(t)
+	output, err := crawler.Crawl(context.Background(), 1, server.URL+"/docs", models.CrawlOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := output.Result
+
+	if len(result.InaccessibleLinks) != 0 {
+		t.Errorf("inaccessible links %v, want none", result.InaccessibleLinks)
+	}
+	if result.InternalLinks != 3 {
+		t.Errorf("internal links = %d, want 3", result.InternalLinks)
+	}
+	if len(result.Forms) != 1 || result.Forms[0].Action != server.URL+"/docs/search" {
+		t.Errorf("forms = %+v, want one submitting to /docs/search", result.Forms)
+	}
+
+	var edges []models.LinkEdge
+	if err := crawler.db.Where("crawl_id = ?", result.ID).Find(&edges).Error; err != nil {
+		t.Fatal(err)
+	}
+	targets := make([]string, 0, len(edges))
+	for _, edge := range edges {
+		// Edges start at the URL the run is stored under, not where it
+		// redirected to
+		if edge.SourceURL != server.URL+"/docs" {
+			t.Errorf("edge source = %s, want %s", edge.SourceURL, server.URL+"/docs")
+		}
+		targets = append(targets, edge.TargetURL)
+	}
+	sort.Strings(targets)
+	want := []string{server.URL + "/about", server.URL + "/docs/intro", server.URL + "/docs/setup"}
+	if len(targets) != len(want) {
+		t.Fatalf("edge targets = %v, want %v", targets, want)
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Errorf("edge targets = %v, want %v", targets, want)
+			break
+		}
+	}
+
+	// The redirected page keeps its outlinks in the link graph
+	graph, err := BuildLinkGraph(strings.TrimPrefix(server.URL, "http://"), []models.CrawlResult{*result}, edges, "", crawler.urls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.NoOutlinks) != 0 {
+		t.Errorf("pages without outlinks = %v, want none", graph.NoOutlinks)
+	}
+	for _, node := range graph.Nodes {
+		switch {
+		case node.URL == server.URL+"/docs":
+			if node.OutDegree != 3 || graph.StartURL != node.URL {
+				t.Errorf("redirected page has out-degree %d and start %s, want 3 and itself", node.OutDegree, graph.StartURL)
+			}
+		case node.InDegree != 1 || node.Depth == nil || *node.Depth != 1:
+			t.Errorf("node %s has in-degree %d and depth %v, want 1 and 1", node.URL, node.InDegree, node.Depth)
+		}
+	}
+}
+
+func TestAnalyzeUploadWithoutBaseURL(t *testing.T) {
+	ctx := context.Background()
+	crawler := newTestCrawler(t)
+
+	first, err := crawler.AnalyzeUpload(ctx, 1, []byte(`<html><body><a href="about.html">About</a><a href="#top">Top</a></body></html>`), "", "page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := crawler.AnalyzeUpload(ctx, 1, []byte(`<html><body>Other</body></html>`), "", "page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := crawler.AnalyzeUpload(ctx, 1, []byte(`<html><body><a href="about.html">About</a><a href="#top">Top</a></body></html>`), "", "page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Result.URL == other.Result.URL {
+		t.Errorf("unrelated uploads are both stored under %s", first.Result.URL)
+	}
+	if first.Result.URL != again.Result.URL {
+		t.Errorf("uploads of the same document are stored under %s and %s", first.Result.URL, again.Result.URL)
+	}
+
+	links := first.Result.Links
+	if len(links.Schemes) != 0 || len(links.Checks) != 0 || len(first.Result.InaccessibleLinks) != 0 {
+		t.Errorf("relative links without a base were followed: schemes %v, checks %v, inaccessible %v",
+			links.Schemes, links.Checks, first.Result.InaccessibleLinks)
+	}
+}
+
+func TestCrawlQueueRunsUploads(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	crawler := newTestCrawler(t)
+	queue := NewCrawlQueue(crawler, 1, 1)
+	queue.Start(ctx)
+
+	body := []byte(`<html><head><title>Uploaded</title></head><body></body></html>`)
+	run, err := crawler.BeginUpload(ctx, 1, body, "", "page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != models.CrawlStatusRunning {
+		t.Errorf("status before the queue ran = %s, want %s", run.Status, models.CrawlStatusRunning)
+	}
+
+	done := make(chan *CrawlOutput, 1)
+	if err := queue.Enqueue(CrawlJob{UserID: 1, URL: run.URL, Run: run, Upload: body, Done: func(output *CrawlOutput, err error) {
+		if err != nil {
+			t.Error(err)
+		}
+		done <- output
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	output := <-done
+
+	var stored models.CrawlResult
+	if err := crawler.db.First(&stored, run.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if output == nil || stored.Status != models.CrawlStatusCompleted || stored.PageTitle != "Uploaded" {
+		t.Errorf("stored upload has status %s and title %q, want completed and Uploaded", stored.Status, stored.PageTitle)
+	}
+	if stored.SourceType != models.SourceTypeUpload {
+		t.Errorf("source type = %s, want %s", stored.SourceType, models.SourceTypeUpload)
+	}
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears bounds the search for the next activation so expressions
+// that can never fire (such as "0 0 30 2 *") do not loop forever
+const cronSearchYears = 5
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+// bits returns the set of every value of the field
+func (f cronField) bits() uint64 {
+	var bits uint64
+	for v := f.min; v <= f.max; v++ {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+var (
+	minuteField  = cronField{0, 59, nil}
+	hourField    = cronField{0, 23, nil}
+	domField     = cronField{1, 31, nil}
+	monthField   = cronField{1, 12, monthNames}
+	weekdayField = cronField{0, 7, weekdayNames}
+)
+
+// CronSchedule is a parsed standard five-field cron expression
+// (minute, hour, day of month, month, day of week)
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record whether the day fields were unrestricted.
+	// When both are restricted a day matches if either field matches.
+	domAny, dowAny bool
+}
+
+// ParseCron parses a cron expression evaluated in loc. Fields support "*",
+// lists, ranges, steps and month/weekday names; the @hourly style macros are
+// accepted too.
+func ParseCron(expr string, loc *time.Location) (*CronSchedule, error) {
+	expr = strings.TrimSpace(strings.ToLower(expr))
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	var s CronSchedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	if s.hour, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	if s.dom, err = parseCronField(fields[2], domField); err != nil {
+		return nil, fmt.Errorf("invalid day of month field: %w", err)
+	}
+	if s.month, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	if s.dow, err = parseCronField(fields[4], weekdayField); err != nil {
+		return nil, fmt.Errorf("invalid day of week field: %w", err)
+	}
+	// Sunday may be written as 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+
+	if s.Next(time.Now().In(loc)).IsZero() {
+		return nil, fmt.Errorf("cron expression never fires")
+	}
+	return &s, nil
+}
+
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*":
+			lo, hi = spec.min, spec.max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0], spec); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(bounds[1], spec); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			v, err := parseCronValue(rangePart, spec)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			// "5/15" means every 15 starting at 5
+			if step > 1 {
+				hi = spec.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, spec cronField) (int, error) {
+	if v, ok := spec.names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, spec.min, spec.max)
+	}
+	return v, nil
+}
+
+// Next returns the first activation strictly after t, in t's location, or
+// the zero time if the schedule does not fire within the next five years.
+//
+// Activations follow the wall clock of the location. When daylight saving
+// time skips a wall time the schedule fires at, it fires at the end of the
+// gap instead; when a wall time repeats, it only fires the first time.
+// Schedules that fire every hour are not shifted and keep firing by elapsed
+// time across both transitions.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + cronSearchYears
+
+	// Every step moves t forward in absolute time. Stepping by wall clock
+	// with time.Date can go backwards inside a daylight saving gap.
+	for t.Year() <= limit {
+		var next time.Time
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			next = advanceTo(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+		case !s.dayMatches(t):
+			next = advanceTo(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			next = nextHour(t)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			next = t.Add(time.Minute)
+		case !s.everyHour() && repeatedWallTime(t):
+			next = t.Add(time.Minute)
+		default:
+			return t
+		}
+		if s.skippedActivation(t, next) {
+			return next
+		}
+		t = next
+	}
+	return time.Time{}
+}
+
+// advanceTo returns next if it is after t, and otherwise the start of the
+// hour following t. time.Date returns an earlier instant when the requested
+// midnight falls into a daylight saving gap.
+func advanceTo(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return nextHour(t)
+}
+
+// nextHour returns the start of the wall clock hour following t
+func nextHour(t time.Time) time.Time {
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
+
+// everyHour reports whether the schedule is not restricted to some hours
+func (s *CronSchedule) everyHour() bool {
+	return s.hour == hourField.bits()
+}
+
+// skippedActivation reports whether the wall clock jumped over an activation
+// of the schedule when time moved from prev to next, as happens when
+// daylight saving time starts
+func (s *CronSchedule) skippedActivation(prev, next time.Time) bool {
+	if s.everyHour() {
+		return false
+	}
+	elapsed := next.Sub(prev)
+	gapStart := wallClock(prev).Add(elapsed)
+	gapEnd := wallClock(next)
+	for wall := gapStart; wall.Before(gapEnd); wall = wall.Add(time.Minute) {
+		if s.month&(1<<uint(wall.Month())) != 0 && s.dayMatches(wall) &&
+			s.hour&(1<<uint(wall.Hour())) != 0 && s.minute&(1<<uint(wall.Minute())) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// wallClock returns the wall clock reading of t as a UTC time, so that wall
+// clock readings can be compared and stepped through without gaps
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+// repeatedWallTime reports whether the wall clock reading of t already
+// occurred earlier, because daylight saving time ended and the clock was
+// turned back
+func repeatedWallTime(t time.Time) bool {
+	_, offset := t.Zone()
+	// Clocks are never turned back by more than a few hours
+	_, before := t.Add(-3 * time.Hour).Zone()
+	if before <= offset {
+		return false
+	}
+	first := t.Add(-time.Duration(before-offset) * time.Second)
+	_, firstOffset := first.Zone()
+	return firstOffset == before
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "* * * * *"},
+		{expr: "*/15 9-17 * * mon-fri"},
+		{expr: "0 0 1,15 jan,jul *"},
+		{expr: "5/10 * * * 7"},
+		{expr: "@daily"},
+		{expr: "  @Hourly  "},
+		{expr: "* * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "* 24 * * *", wantErr: true},
+		{expr: "* * 0 * *", wantErr: true},
+		{expr: "* * * 13 *", wantErr: true},
+		{expr: "* * * * 8", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "5-1 * * * *", wantErr: true},
+		{expr: "* * * foo *", wantErr: true},
+		{expr: "0 0 30 2 *", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseCron(tt.expr, time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCron(%q) error = %v, want error %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	lordHowe := mustLoadLocation(t, "Australia/Lord_Howe")
+	kolkata := mustLoadLocation(t, "Asia/Kolkata")
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "every minute",
+			expr: "* * * * *",
+			from: time.Date(2026, 1, 1, 10, 0, 30, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 1, 1, 10, 1, 0, 0, time.UTC),
+				time.Date(2026, 1, 1, 10, 2, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "strictly after an activation",
+			expr: "0 9 * * *",
+			from: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "weekdays",
+			expr: "30 8 * * mon-fri",
+			from: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC), // Friday
+			want: []time.Time{
+				time.Date(2026, 1, 5, 8, 30, 0, 0, time.UTC),
+				time.Date(2026, 1, 6, 8, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 0 13 * fri",
+			from: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 2, 6, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "leap day",
+			expr: "0 12 29 2 *",
+			from: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "half hour offset",
+			expr: "0 9 * * *",
+			from: time.Date(2026, 1, 1, 9, 30, 0, 0, kolkata),
+			want: []time.Time{time.Date(2026, 1, 2, 9, 0, 0, 0, kolkata)},
+		},
+		{
+			name: "daily across the spring forward gap",
+			expr: "0 9 * * *",
+			from: time.Date(2026, 3, 7, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "activation inside the gap fires at its end",
+			expr: "30 2 * * *",
+			from: time.Date(2026, 3, 7, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 7, 0, 0, 0, time.UTC), // 03:00 EDT
+				time.Date(2026, 3, 9, 6, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "hourly across the gap",
+			expr: "30 * * * *",
+			from: time.Date(2026, 3, 8, 1, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 6, 30, 0, 0, time.UTC), // 01:30 EST
+				time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC), // 03:30 EDT
+			},
+		},
+		{
+			name: "repeated wall time fires once",
+			expr: "30 1 * * *",
+			from: time.Date(2026, 10, 31, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+				time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "hourly across the fall back overlap",
+			expr: "0 * * * *",
+			from: time.Date(2026, 11, 1, 4, 30, 0, 0, time.UTC).In(newYork),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 0, 0, 0, time.UTC), // 01:00 EDT
+				time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC), // 01:00 EST
+				time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC), // 02:00 EST
+			},
+		},
+		{
+			name: "half hour daylight saving gap",
+			expr: "15 2 * * *",
+			from: time.Date(2026, 10, 3, 12, 0, 0, 0, lordHowe),
+			want: []time.Time{
+				time.Date(2026, 10, 4, 2, 30, 0, 0, lordHowe),
+				time.Date(2026, 10, 5, 2, 15, 0, 0, lordHowe),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr, tt.from.Location())
+			if err != nil {
+				t.Fatal(err)
+			}
+			from := tt.from
+			for _, want := range tt.want {
+				got := schedule.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("Next(%v) = %v, want %v", from, got, want.In(from.Location()))
+				}
+				if got.Location() != tt.from.Location() {
+					t.Errorf("Next returned a time in %v, want %v", got.Location(), tt.from.Location())
+				}
+				from = got
+			}
+		})
+	}
+}
+
+// TestCronNextTerminates evaluates fixed-time schedules from every hour
+// around the daylight saving transitions of several zones
+func TestCronNextTerminates(t *testing.T) {
+	zones := []string{"America/New_York", "Europe/London", "Australia/Lord_Howe", "America/Santiago", "Asia/Tehran"}
+	exprs := []string{"0 9 * * *", "30 2 * * *", "0 0 * * *", "*/20 * * * *", "0 0 1 * *"}
+
+	for _, zone := range zones {
+		loc := mustLoadLocation(t, zone)
+		for _, expr := range exprs {
+			schedule, err := ParseCron(expr, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+			for from := start; from.Year() == 2026; from = from.Add(7 * time.Hour) {
+				next := schedule.Next(from)
+				if !next.After(from) || next.Sub(from) > 32*24*time.Hour {
+					t.Fatalf("%s in %s: Next(%v) = %v", expr, zone, from, next)
+				}
+			}
+		}
+	}
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// DiffCrawls compares two crawl results of the same URL
+func DiffCrawls(from, to models.CrawlResult) models.CrawlDiff {
+	diff := models.CrawlDiff{
+		URL:         to.URL,
+		From:        models.CrawlRef{ID: from.ID, CreatedAt: from.CreatedAt},
+		To:          models.CrawlRef{ID: to.ID, CreatedAt: to.CreatedAt},
+		PageTitle:   stringChange(from.PageTitle, to.PageTitle),
+		HTMLVersion: stringChange(from.HTMLVersion, to.HTMLVersion),
+		Headings: models.HeadingDeltas{
+			H1: intDelta(from.Headings.H1, to.Headings.H1),
+			H2: intDelta(from.Headings.H2, to.Headings.H2),
+			H3: intDelta(from.Headings.H3, to.Headings.H3),
+			H4: intDelta(from.Headings.H4, to.Headings.H4),
+			H5: intDelta(from.Headings.H5, to.Headings.H5),
+			H6: intDelta(from.Headings.H6, to.Headings.H6),
+		},
+		InternalLinks:            intDelta(from.InternalLinks, to.InternalLinks),
+		ExternalLinks:            intDelta(from.ExternalLinks, to.ExternalLinks),
+		InaccessibleLinksAdded:   setDifference(to.InaccessibleLinks, from.InaccessibleLinks),
+		InaccessibleLinksRemoved: setDifference(from.InaccessibleLinks, to.InaccessibleLinks),
+		HasLoginForm: models.BoolChange{
+			From:    from.HasLoginForm,
+			To:      to.HasLoginForm,
+			Changed: from.HasLoginForm != to.HasLoginForm,
+		},
+	}
+
+	h := diff.Headings
+	diff.Changed = diff.PageTitle.Changed ||
+		diff.HTMLVersion.Changed ||
+		h.H1.Delta != 0 || h.H2.Delta != 0 || h.H3.Delta != 0 ||
+		h.H4.Delta != 0 || h.H5.Delta != 0 || h.H6.Delta != 0 ||
+		diff.InternalLinks.Delta != 0 ||
+		diff.ExternalLinks.Delta != 0 ||
+		len(diff.InaccessibleLinksAdded) > 0 ||
+		len(diff.InaccessibleLinksRemoved) > 0 ||
+		diff.HasLoginForm.Changed
+
+	return diff
+}
+
+func stringChange(from, to string) models.StringChange {
+	return models.StringChange{From: from, To: to, Changed: from != to}
+}
+
+func intDelta(from, to int) models.IntDelta {
+	return models.IntDelta{From: from, To: to, Delta: to - from}
+}
+
+// setDifference returns the values of a that are not in b, in the order of a
+func setDifference(a, b []string) []string {
+	exclude := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		exclude[v] = struct{}{}
+	}
+
+	result := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, v := range a {
+		if _, ok := exclude[v]; ok {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
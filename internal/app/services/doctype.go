@@ -0,0 +1,277 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"golang.org/x/net/html"
+)
+
+// xhtmlNamespace is the namespace the root of an XHTML document must declare
+const xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+
+// doctypeVersions maps the public identifiers of known doctypes, lower
+// cased, to the version they declare
+var doctypeVersions = map[string]string{
+	"-//w3c//dtd xhtml 1.0 strict//en":                      "XHTML 1.0 Strict",
+	"-//w3c//dtd xhtml 1.0 transitional//en":                "XHTML 1.0 Transitional",
+	"-//w3c//dtd xhtml 1.0 frameset//en":                    "XHTML 1.0 Frameset",
+	"-//w3c//dtd xhtml 1.1//en":                             "XHTML 1.1",
+	"-//w3c//dtd xhtml basic 1.0//en":                       "XHTML Basic 1.0",
+	"-//w3c//dtd xhtml basic 1.1//en":                       "XHTML Basic 1.1",
+	"-//w3c//dtd xhtml+rdfa 1.0//en":                        "XHTML+RDFa 1.0",
+	"-//w3c//dtd xhtml+rdfa 1.1//en":                        "XHTML+RDFa 1.1",
+	"-//wapforum//dtd xhtml mobile 1.0//en":                 "XHTML Mobile 1.0",
+	"-//wapforum//dtd xhtml mobile 1.1//en":                 "XHTML Mobile 1.1",
+	"-//wapforum//dtd xhtml mobile 1.2//en":                 "XHTML Mobile 1.2",
+	"-//w3c//dtd html 4.01//en":                             "HTML 4.01 Strict",
+	"-//w3c//dtd html 4.01 transitional//en":                "HTML 4.01 Transitional",
+	"-//w3c//dtd html 4.01 frameset//en":                    "HTML 4.01 Frameset",
+	"-//w3c//dtd html 4.0//en":                              "HTML 4.0 Strict",
+	"-//w3c//dtd html 4.0 transitional//en":                 "HTML 4.0 Transitional",
+	"-//w3c//dtd html 4.0 frameset//en":                     "HTML 4.0 Frameset",
+	"-//w3c//dtd html 3.2 final//en":                        "HTML 3.2",
+	"-//w3c//dtd html 3.2//en":                              "HTML 3.2",
+	"-//w3c//dtd html 3.2 draft//en":                        "HTML 3.2",
+	"-//ietf//dtd html 3.0//en":                             "HTML 3.0",
+	"-//w3o//dtd w3 html 3.0//en":                           "HTML 3.0",
+	"-//ietf//dtd html 2.0//en":                             "HTML 2.0",
+	"-//ietf//dtd html 2.0 strict//en":                      "HTML 2.0 Strict",
+	"-//ietf//dtd html//en":                                 "HTML 2.0",
+	"iso/iec 15445:2000//dtd html//en":                      "ISO/IEC 15445:2000",
+	"iso/iec 15445:2000//dtd hypertext markup language//en": "ISO/IEC 15445:2000",
+}
+
+// Public identifiers that trigger quirks mode, exactly or as prefixes, per
+// the WHATWG "initial" insertion mode
+var (
+	quirksPublicIDs = []string{
+		"-//w3o//dtd w3 html strict 3.0//en//",
+		"-/w3c/dtd html 4.0 transitional/en",
+		"html",
+	}
+	quirksPublicIDPrefixes = []string{
+		"+//silmaril//dtd html pro v0r11 19970101//",
+		"-//as//dtd html 3.0 aswedit + extensions//",
+		"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+		"-//ietf//dtd html 2.0 level 1//",
+		"-//ietf//dtd html 2.0 level 2//",
+		"-//ietf//dtd html 2.0 strict level 1//",
+		"-//ietf//dtd html 2.0 strict level 2//",
+		"-//ietf//dtd html 2.0 strict//",
+		"-//ietf//dtd html 2.0//",
+		"-//ietf//dtd html 2.1e//",
+		"-//ietf//dtd html 3.0//",
+		"-//ietf//dtd html 3.2 final//",
+		"-//ietf//dtd html 3.2//",
+		"-//ietf//dtd html 3//",
+		"-//ietf//dtd html level 0//",
+		"-//ietf//dtd html level 1//",
+		"-//ietf//dtd html level 2//",
+		"-//ietf//dtd html level 3//",
+		"-//ietf//dtd html strict level 0//",
+		"-//ietf//dtd html strict level 1//",
+		"-//ietf//dtd html strict level 2//",
+		"-//ietf//dtd html strict level 3//",
+		"-//ietf//dtd html strict//",
+		"-//ietf//dtd html//",
+		"-//metrius//dtd metrius presentational//",
+		"-//microsoft//dtd internet explorer 2.0 html strict//",
+		"-//microsoft//dtd internet explorer 2.0 html//",
+		"-//microsoft//dtd internet explorer 2.0 tables//",
+		"-//microsoft//dtd internet explorer 3.0 html strict//",
+		"-//microsoft//dtd internet explorer 3.0 html//",
+		"-//microsoft//dtd internet explorer 3.0 tables//",
+		"-//netscape comm. corp.//dtd html//",
+		"-//netscape comm. corp.//dtd strict html//",
+		"-//o'reilly and associates//dtd html 2.0//",
+		"-//o'reilly and associates//dtd html extended 1.0//",
+		"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+		"-//sq//dtd html 2.0 hotmetal + extensions//",
+		"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+		"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+		"-//spyglass//dtd html 2.0 extended//",
+		"-//sun microsystems corp.//dtd hotjava html//",
+		"-//sun microsystems corp.//dtd hotjava strict html//",
+		"-//w3c//dtd html 3 1995-03-24//",
+		"-//w3c//dtd html 3.2 draft//",
+		"-//w3c//dtd html 3.2 final//",
+		"-//w3c//dtd html 3.2//",
+		"-//w3c//dtd html 3.2s draft//",
+		"-//w3c//dtd html 4.0 frameset//",
+		"-//w3c//dtd html 4.0 transitional//",
+		"-//w3c//dtd html experimental 19960712//",
+		"-//w3c//dtd html experimental 970421//",
+		"-//w3c//dtd w3 html//",
+		"-//w3o//dtd w3 html 3.0//",
+		"-//webtechs//dtd mozilla html 2.0//",
+		"-//webtechs//dtd mozilla html//",
+	}
+	quirksSystemID = "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd"
+	// Quirks without a system identifier, limited quirks with one
+	html401LoosePrefixes = []string{
+		"-//w3c//dtd html 4.01 frameset//",
+		"-//w3c//dtd html 4.01 transitional//",
+	}
+	limitedQuirksPrefixes = []string{
+		"-//w3c//dtd xhtml 1.0 frameset//",
+		"-//w3c//dtd xhtml 1.0 transitional//",
+	}
+)
+
+// AnalyzeDoctype reads the doctype of a page, names the version it
+// declares, works out the rendering mode it triggers and, for XHTML pages,
+// checks the root namespace and well-formedness
+func AnalyzeDoctype(body []byte) models.DoctypeInfo {
+	info := models.DoctypeInfo{Version: "Unknown", DocumentMode: models.DocumentModeQuirks}
+
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return info
+	}
+
+	var root *html.Node
+	for n := doc.FirstChild; n != nil; n = n.NextSibling {
+		switch n.Type {
+		case html.DoctypeNode:
+			// The parser only keeps a doctype that precedes all content
+			info.Present = true
+			info.Name = n.Data
+			for _, attr := range n.Attr {
+				switch attr.Key {
+				case "public":
+					info.PublicID = attr.Val
+				case "system":
+					info.SystemID = attr.Val
+				}
+			}
+		case html.ElementNode:
+			root = n
+		}
+	}
+
+	if info.Present {
+		info.Version = doctypeVersion(info)
+		info.DocumentMode = documentMode(info)
+	}
+
+	namespace, hasNamespace := rootNamespace(root)
+	if strings.HasPrefix(info.Version, "XHTML") || hasNamespace {
+		check := &models.XHTMLCheck{Namespace: namespace, NamespaceValid: namespace == xhtmlNamespace}
+		check.WellFormed, check.Error, check.ErrorLine = checkWellFormed(body)
+		info.XHTML = check
+	}
+	return info
+}
+
+// doctypeVersion names the version declared by a doctype
+func doctypeVersion(info models.DoctypeInfo) string {
+	public := strings.ToLower(strings.TrimSpace(info.PublicID))
+	if version, ok := doctypeVersions[public]; ok {
+		return version
+	}
+	if strings.EqualFold(info.Name, "html") && public == "" &&
+		(info.SystemID == "" || strings.EqualFold(info.SystemID, "about:legacy-compat")) {
+		return "HTML5"
+	}
+
+	// Unlisted variants of known versions, such as other languages
+	for _, v := range []struct{ marker, version string }{
+		{"xhtml 1.0 strict", "XHTML 1.0 Strict"},
+		{"xhtml 1.0 transitional", "XHTML 1.0 Transitional"},
+		{"xhtml 1.0 frameset", "XHTML 1.0 Frameset"},
+		{"xhtml 1.1", "XHTML 1.1"},
+		{"html 4.01 transitional", "HTML 4.01 Transitional"},
+		{"html 4.01 frameset", "HTML 4.01 Frameset"},
+		{"html 4.01", "HTML 4.01 Strict"},
+		{"html 4.0 transitional", "HTML 4.0 Transitional"},
+		{"html 4.0 frameset", "HTML 4.0 Frameset"},
+		{"html 4.0", "HTML 4.0 Strict"},
+		{"html 3.2", "HTML 3.2"},
+		{"html 2.0", "HTML 2.0"},
+	} {
+		if strings.Contains(public, v.marker) {
+			return v.version
+		}
+	}
+	return "Unknown"
+}
+
+// documentMode applies the WHATWG rules for the rendering mode a doctype
+// triggers
+func documentMode(info models.DoctypeInfo) string {
+	public := strings.ToLower(info.PublicID)
+	system := strings.ToLower(info.SystemID)
+
+	if !strings.EqualFold(info.Name, "html") || system == quirksSystemID {
+		return models.DocumentModeQuirks
+	}
+	for _, id := range quirksPublicIDs {
+		if public == id {
+			return models.DocumentModeQuirks
+		}
+	}
+	for _, prefix := range quirksPublicIDPrefixes {
+		if strings.HasPrefix(public, prefix) {
+			return models.DocumentModeQuirks
+		}
+	}
+	for _, prefix := range html401LoosePrefixes {
+		if strings.HasPrefix(public, prefix) {
+			if info.SystemID == "" {
+				return models.DocumentModeQuirks
+			}
+			return models.DocumentModeLimitedQuirks
+		}
+	}
+	for _, prefix := range limitedQuirksPrefixes {
+		if strings.HasPrefix(public, prefix) {
+			return models.DocumentModeLimitedQuirks
+		}
+	}
+	return models.DocumentModeStandards
+}
+
+// rootNamespace returns the xmlns attribute of the root element
+func rootNamespace(root *html.Node) (string, bool) {
+	if root == nil {
+		return "", false
+	}
+	for _, attr := range root.Attr {
+		if attr.Namespace == "" && attr.Key == "xmlns" {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// checkWellFormed parses a page as XML and returns its first error with
+// the line it was found on
+func checkWellFormed(body []byte) (bool, string, int) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = true
+	decoder.Entity = xml.HTMLEntity
+	// The charset was already handled when the page was fetched
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return true, "", 0
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return false, syntaxErr.Msg, syntaxErr.Line
+			}
+			line, _ := decoder.InputPos()
+			return false, err.Error(), line
+		}
+	}
+}
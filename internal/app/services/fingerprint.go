@@ -0,0 +1,358 @@
+package services
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// bundledTechRules are the technology fingerprint rules shipped with the
+// binary, used unless a rules file is configured
+//
+//go:embed rules/technologies.json
+var bundledTechRules []byte
+
+// techRuleSpec is a technology as written in a rules file. Patterns are
+// case-insensitive regular expressions, optionally followed by
+// "\;version:\1" to take the version from a capture group. An empty header,
+// cookie or meta pattern only requires it to be present, and a cookie name
+// ending in "*" matches a prefix.
+type techRuleSpec struct {
+	Categories  []string          `json:"categories"`
+	Headers     map[string]string `json:"headers"`
+	Cookies     map[string]string `json:"cookies"`
+	Meta        map[string]string `json:"meta"`
+	Scripts     []string          `json:"scripts"`
+	Stylesheets []string          `json:"stylesheets"`
+	HTML        []string          `json:"html"`
+	Implies     []string          `json:"implies"`
+}
+
+// techPattern is a compiled rule pattern
+type techPattern struct {
+	re      *regexp.Regexp
+	version string
+}
+
+// match reports whether value matches and the version it reveals, if any
+func (p techPattern) match(value string) (bool, string) {
+	if p.re == nil {
+		return true, ""
+	}
+	groups := p.re.FindStringSubmatch(value)
+	if groups == nil {
+		return false, ""
+	}
+	return true, expandVersion(p.version, groups)
+}
+
+// techRule is a compiled technology rule
+type techRule struct {
+	name        string
+	categories  []string
+	headers     map[string]techPattern
+	cookies     map[string]techPattern
+	meta        map[string]techPattern
+	scripts     []techPattern
+	stylesheets []techPattern
+	html        []techPattern
+	implies     []string
+}
+
+// Fingerprinter detects the technologies a page is built with from its
+// response headers, cookies, meta tags, script and stylesheet URLs and HTML
+type Fingerprinter struct {
+	rules  []techRule
+	byName map[string]*techRule
+}
+
+// LoadFingerprinter loads the fingerprint rules of the file at path, or the
+// bundled rules if path is empty
+func LoadFingerprinter(path string) (*Fingerprinter, error) {
+	if path == "" {
+		return NewFingerprinter(bundledTechRules)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read technology rules: %w", err)
+	}
+	return NewFingerprinter(data)
+}
+
+// NewFingerprinter compiles fingerprint rules: a JSON object mapping
+// technology names to their rules
+func NewFingerprinter(data []byte) (*Fingerprinter, error) {
+	var specs map[string]techRuleSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("failed to parse technology rules: %w", err)
+	}
+
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	f := &Fingerprinter{
+		rules:  make([]techRule, 0, len(specs)),
+		byName: make(map[string]*techRule, len(specs)),
+	}
+	for _, name := range names {
+		rule, err := compileTechRule(name, specs[name])
+		if err != nil {
+			return nil, err
+		}
+		f.rules = append(f.rules, rule)
+	}
+	for i := range f.rules {
+		f.byName[f.rules[i].name] = &f.rules[i]
+	}
+	for _, rule := range f.rules {
+		for _, implied := range rule.implies {
+			if f.byName[implied] == nil {
+				return nil, fmt.Errorf("technology %q implies unknown technology %q", rule.name, implied)
+			}
+		}
+	}
+	return f, nil
+}
+
+func compileTechRule(name string, spec techRuleSpec) (techRule, error) {
+	rule := techRule{
+		name:       name,
+		categories: spec.Categories,
+		headers:    make(map[string]techPattern, len(spec.Headers)),
+		cookies:    make(map[string]techPattern, len(spec.Cookies)),
+		meta:       make(map[string]techPattern, len(spec.Meta)),
+		implies:    spec.Implies,
+	}
+	if rule.categories == nil {
+		rule.categories = make([]string, 0)
+	}
+
+	var err error
+	compileMap := func(dest map[string]techPattern, src map[string]string, key func(string) string) {
+		for k, v := range src {
+			if err != nil {
+				return
+			}
+			var p techPattern
+			if p, err = compileTechPattern(name, v); err == nil {
+				dest[key(k)] = p
+			}
+		}
+	}
+	compileList := func(src []string) []techPattern {
+		list := make([]techPattern, 0, len(src))
+		for _, v := range src {
+			if err != nil {
+				return nil
+			}
+			var p techPattern
+			if p, err = compileTechPattern(name, v); err == nil {
+				list = append(list, p)
+			}
+		}
+		return list
+	}
+
+	compileMap(rule.headers, spec.Headers, http.CanonicalHeaderKey)
+	compileMap(rule.cookies, spec.Cookies, func(k string) string { return k })
+	compileMap(rule.meta, spec.Meta, strings.ToLower)
+	rule.scripts = compileList(spec.Scripts)
+	rule.stylesheets = compileList(spec.Stylesheets)
+	rule.html = compileList(spec.HTML)
+	return rule, err
+}
+
+func compileTechPattern(name, pattern string) (techPattern, error) {
+	expr, version, _ := strings.Cut(pattern, `\;version:`)
+	if expr == "" {
+		return techPattern{version: version}, nil
+	}
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return techPattern{}, fmt.Errorf("invalid pattern %q for technology %q: %w", pattern, name, err)
+	}
+	return techPattern{re: re, version: version}, nil
+}
+
+// versionGroup matches the capture group references of a version template
+var versionGroup = regexp.MustCompile(`\\(\d+)`)
+
+// expandVersion fills the capture group references of a version template
+func expandVersion(template string, groups []string) string {
+	if template == "" {
+		return ""
+	}
+	version := versionGroup.ReplaceAllStringFunc(template, func(ref string) string {
+		n, _ := strconv.Atoi(ref[1:])
+		if n < len(groups) {
+			return groups[n]
+		}
+		return ""
+	})
+	return strings.TrimSpace(version)
+}
+
+// pageSignals are the parts of a page the fingerprint rules match against
+type pageSignals struct {
+	header      http.Header
+	cookies     []*http.Cookie
+	meta        map[string][]string
+	scripts     []string
+	stylesheets []string
+	html        string
+}
+
+// Detect returns the technologies of a page, sorted by name. header holds
+// the response headers and may be nil for uploaded pages.
+func (f *Fingerprinter) Detect(header http.Header, doc *goquery.Document, body []byte) models.TechnologyList {
+	signals := pageSignals{
+		header: header,
+		meta:   make(map[string][]string),
+		html:   string(body),
+	}
+	if header != nil {
+		signals.cookies = (&http.Response{Header: header}).Cookies()
+	}
+	doc.Find("meta[content]").Each(func(i int, s *goquery.Selection) {
+		content, _ := s.Attr("content")
+		for _, attr := range []string{"name", "property"} {
+			if key, ok := s.Attr(attr); ok && key != "" {
+				key = strings.ToLower(strings.TrimSpace(key))
+				signals.meta[key] = append(signals.meta[key], content)
+			}
+		}
+	})
+	doc.Find("script[src]").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		signals.scripts = append(signals.scripts, src)
+	})
+	doc.Find("link[href]").Each(func(i int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		href, _ := s.Attr("href")
+		for _, r := range strings.Fields(strings.ToLower(rel)) {
+			if r == "stylesheet" {
+				signals.stylesheets = append(signals.stylesheets, href)
+				break
+			}
+		}
+	})
+
+	found := make(map[string]*models.Technology)
+	for i := range f.rules {
+		rule := &f.rules[i]
+		if matched, version := rule.match(&signals); matched {
+			found[rule.name] = &models.Technology{Name: rule.name, Version: version, Categories: rule.categories}
+		}
+	}
+
+	// Add the technologies implied by the detected ones
+	pending := make([]string, 0, len(found))
+	for name := range found {
+		pending = append(pending, name)
+	}
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, implied := range f.byName[name].implies {
+			if _, ok := found[implied]; ok {
+				continue
+			}
+			found[implied] = &models.Technology{Name: implied, Categories: f.byName[implied].categories, Implied: true}
+			pending = append(pending, implied)
+		}
+	}
+
+	technologies := make(models.TechnologyList, 0, len(found))
+	for _, tech := range found {
+		technologies = append(technologies, *tech)
+	}
+	sort.Slice(technologies, func(i, j int) bool {
+		return technologies[i].Name < technologies[j].Name
+	})
+	return technologies
+}
+
+// match reports whether any pattern of the rule matches the page and the
+// most specific version revealed by the matches
+func (r *techRule) match(signals *pageSignals) (bool, string) {
+	matched := false
+	version := ""
+	check := func(p techPattern, value string) {
+		ok, v := p.match(value)
+		if !ok {
+			return
+		}
+		matched = true
+		if len(v) > len(version) {
+			version = v
+		}
+	}
+
+	for name, p := range r.headers {
+		for _, value := range signals.header.Values(name) {
+			check(p, value)
+		}
+	}
+	for name, p := range r.cookies {
+		for _, cookie := range signals.cookies {
+			if matchCookieName(name, cookie.Name) {
+				check(p, cookie.Value)
+			}
+		}
+	}
+	for name, p := range r.meta {
+		for _, content := range signals.meta[name] {
+			check(p, content)
+		}
+	}
+	for _, p := range r.scripts {
+		for _, src := range signals.scripts {
+			check(p, src)
+		}
+	}
+	for _, p := range r.stylesheets {
+		for _, href := range signals.stylesheets {
+			check(p, href)
+		}
+	}
+	for _, p := range r.html {
+		check(p, signals.html)
+	}
+	return matched, version
+}
+
+// matchCookieName matches a cookie name against a rule name, where a
+// trailing "*" matches a prefix
+func matchCookieName(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return pattern == name
+}
+
+// technologyNames flattens detected technologies into the lower-cased,
+// comma-delimited form stored for list filtering, e.g. ",nginx,php,"
+func technologyNames(technologies models.TechnologyList) string {
+	if len(technologies) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte(',')
+	for _, tech := range technologies {
+		b.WriteString(strings.ToLower(tech.Name))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
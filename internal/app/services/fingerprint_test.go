@@ -0,0 +1,169 @@
+package services
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+const testTechRules = `{
+	"Nginx": {
+		"categories": ["Web servers"],
+		"headers": {"Server": "^nginx(?:/([\\d.]+))?\\;version:\\1"}
+	},
+	"PHP": {
+		"categories": ["Languages"],
+		"headers": {"X-Powered-By": "^PHP/([\\d.]+)\\;version:\\1"},
+		"cookies": {"PHPSESSID": ""}
+	},
+	"WordPress": {
+		"categories": ["CMS"],
+		"meta": {"generator": "^WordPress ?([\\d.]+)?\\;version:\\1"},
+		"scripts": ["/wp-includes/"],
+		"implies": ["PHP", "MySQL"]
+	},
+	"MySQL": {
+		"categories": ["Databases"]
+	},
+	"Magento": {
+		"categories": ["Ecommerce"],
+		"cookies": {"mage-*": ""},
+		"html": ["<script[^>]+data-requiremodule=\"mage/"]
+	},
+	"Bootstrap": {
+		"categories": ["UI frameworks"],
+		"stylesheets": ["/bootstrap@([\\d.]+)/\\;version:\\1"]
+	}
+}`
+
+func TestNewFingerprinter(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{name: "valid", rules: testTechRules},
+		{name: "invalid JSON", rules: `{"Nginx": [`, wantErr: "failed to parse technology rules"},
+		{name: "invalid pattern", rules: `{"Nginx": {"headers": {"Server": "nginx("}}}`, wantErr: `invalid pattern "nginx(" for technology "Nginx"`},
+		{
+			name:    "unknown implied technology",
+			rules:   `{"WordPress": {"html": ["wp-content"], "implies": ["PHP"]}}`,
+			wantErr: `technology "WordPress" implies unknown technology "PHP"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFingerprinter([]byte(tt.rules))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadFingerprinter(""); err != nil {
+		t.Errorf("bundled rules do not load: %v", err)
+	}
+}
+
+func TestFingerprinterDetect(t *testing.T) {
+	f, err := NewFingerprinter([]byte(testTechRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tech := func(name, version string, implied bool, categories ...string) models.Technology {
+		return models.Technology{Name: name, Version: version, Categories: categories, Implied: implied}
+	}
+	tests := []struct {
+		name   string
+		header http.Header
+		html   string
+		want   models.TechnologyList
+	}{
+		{name: "nothing", header: http.Header{"Server": {"Apache"}}, html: `<p>Hello</p>`, want: models.TechnologyList{}},
+		{
+			name:   "header version",
+			header: http.Header{"Server": {"NGINX/1.25.3"}},
+			want:   models.TechnologyList{tech("Nginx", "1.25.3", false, "Web servers")},
+		},
+		{
+			name:   "header without version",
+			header: http.Header{"Server": {"nginx"}},
+			want:   models.TechnologyList{tech("Nginx", "", false, "Web servers")},
+		},
+		{
+			name: "implies",
+			html: `<meta name="Generator" content="WordPress 6.4.2">`,
+			want: models.TechnologyList{
+				tech("MySQL", "", true, "Databases"),
+				tech("PHP", "", true, "Languages"),
+				tech("WordPress", "6.4.2", false, "CMS"),
+			},
+		},
+		{
+			name:   "implied technology also matched",
+			header: http.Header{"X-Powered-By": {"PHP/8.2.1"}},
+			html:   `<script src="/wp-includes/js/jquery.js"></script>`,
+			want: models.TechnologyList{
+				tech("MySQL", "", true, "Databases"),
+				tech("PHP", "8.2.1", false, "Languages"),
+				tech("WordPress", "", false, "CMS"),
+			},
+		},
+		{
+			name:   "cookie prefix",
+			header: http.Header{"Set-Cookie": {"mage-cache-sessid=true; Path=/"}},
+			want:   models.TechnologyList{tech("Magento", "", false, "Ecommerce")},
+		},
+		{
+			name:   "cookie name must match exactly without a wildcard",
+			header: http.Header{"Set-Cookie": {"PHPSESSID2=abc", "magento=1"}},
+			want:   models.TechnologyList{},
+		},
+		{
+			name: "html and stylesheet",
+			html: `<link rel="preload stylesheet" href="https://cdn.example/bootstrap@5.3.2/dist/css/bootstrap.min.css">
+				<script type="text/x-magento-init" data-requiremodule="mage/cookies"></script>`,
+			want: models.TechnologyList{
+				tech("Bootstrap", "5.3.2", false, "UI frameworks"),
+				tech("Magento", "", false, "Ecommerce"),
+			},
+		},
+		{
+			name: "uploads have no headers",
+			html: `<link rel="icon" href="/bootstrap@5.3.2/icon.png">`,
+			want: models.TechnologyList{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := f.Detect(tt.header, doc, []byte(tt.html))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTechnologyNames(t *testing.T) {
+	if got := technologyNames(nil); got != "" {
+		t.Errorf("technologyNames(nil) = %q, want empty", got)
+	}
+	list := models.TechnologyList{{Name: "Nginx"}, {Name: "Ruby on Rails"}}
+	if got := technologyNames(list); got != ",nginx,ruby on rails," {
+		t.Errorf("technologyNames() = %q, want \",nginx,ruby on rails,\"", got)
+	}
+}
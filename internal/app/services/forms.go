@@ -0,0 +1,151 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// csrfFieldMarkers are substrings of the names of hidden inputs commonly
+// used to carry anti-CSRF tokens
+var csrfFieldMarkers = []string{
+	"csrf", "xsrf", "authenticity_token", "requestverificationtoken", "_token", "nonce",
+}
+
+// AnalyzeForms inventories the forms of a page. Actions are resolved against
+// the page URL or its <base href>; a form without an action submits to the
+// page itself.
+func AnalyzeForms(doc *goquery.Document, page *url.URL) models.FormInventory {
+	base := documentBase(doc, page)
+	forms := make(models.FormInventory, 0)
+
+	doc.Find("form").Each(func(i int, s *goquery.Selection) {
+		form := models.Form{
+			Position:     i + 1,
+			ID:           s.AttrOr("id", ""),
+			Name:         s.AttrOr("name", ""),
+			Method:       formMethod(s.AttrOr("method", "")),
+			Enctype:      formEnctype(s.AttrOr("enctype", "")),
+			Autocomplete: "on",
+			Fields:       make([]models.FormField, 0),
+		}
+		if strings.EqualFold(strings.TrimSpace(s.AttrOr("autocomplete", "")), "off") {
+			form.Autocomplete = "off"
+		}
+
+		action := page
+		if raw := strings.TrimSpace(s.AttrOr("action", "")); raw != "" {
+			if resolved, err := base.Parse(raw); err == nil {
+				action = resolved
+			}
+		}
+		form.Action = action.String()
+		form.CrossOrigin = !sameOrigin(action, page)
+		form.Insecure = strings.EqualFold(action.Scheme, "http")
+
+		// Controls outside the form element can join it with form="id"
+		controls := s.Find("input, select, textarea")
+		if form.ID != "" {
+			controls = controls.AddSelection(doc.Find("input, select, textarea").FilterFunction(func(_ int, c *goquery.Selection) bool {
+				return c.AttrOr("form", "") == form.ID
+			}))
+		}
+
+		controls.Each(func(_ int, c *goquery.Selection) {
+			field := formField(c)
+			switch field.Type {
+			case "submit", "reset", "button", "image":
+				return
+			case "file":
+				form.HasFileUpload = true
+			case "password":
+				form.HasPassword = true
+			case "hidden":
+				if !form.HasCSRFToken && isCSRFField(field.Name) {
+					form.HasCSRFToken = true
+					form.CSRFField = field.Name
+				}
+			}
+			if field.Name == "" {
+				return
+			}
+			form.Fields = append(form.Fields, field)
+		})
+
+		forms = append(forms, form)
+	})
+
+	return forms
+}
+
+// formField describes a form control; the type of inputs defaults to text
+func formField(c *goquery.Selection) models.FormField {
+	fieldType := goquery.NodeName(c)
+	if fieldType == "input" {
+		fieldType = strings.ToLower(strings.TrimSpace(c.AttrOr("type", "")))
+		if fieldType == "" {
+			fieldType = "text"
+		}
+	}
+	_, required := c.Attr("required")
+	return models.FormField{
+		Name:         c.AttrOr("name", ""),
+		Type:         fieldType,
+		Required:     required,
+		Autocomplete: strings.TrimSpace(c.AttrOr("autocomplete", "")),
+	}
+}
+
+// formMethod returns the upper case submission method; invalid and missing
+// methods default to GET
+func formMethod(method string) string {
+	switch m := strings.ToUpper(strings.TrimSpace(method)); m {
+	case "POST", "DIALOG":
+		return m
+	default:
+		return "GET"
+	}
+}
+
+// formEnctype returns the encoding of the form data; invalid and missing
+// values default to application/x-www-form-urlencoded
+func formEnctype(enctype string) string {
+	switch e := strings.ToLower(strings.TrimSpace(enctype)); e {
+	case "multipart/form-data", "text/plain":
+		return e
+	default:
+		return "application/x-www-form-urlencoded"
+	}
+}
+
+func isCSRFField(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range csrfFieldMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameOrigin compares the scheme, host and effective port of two URLs
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) &&
+		strings.EqualFold(a.Hostname(), b.Hostname()) &&
+		effectivePort(a) == effectivePort(b)
+}
+
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+func TestAnalyzeForms(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head>
+		<base href="https://example.com/app/">
+	</head><body>
+		<form id="login" method="post" action="session" autocomplete="OFF">
+			<input name="email" type="EMAIL" required autocomplete="username">
+			<input name="password" type="password" required>
+			<input type="hidden" name="authenticity_token" value="x">
+			<input type="hidden" name="_csrf" value="y">
+			<input type="checkbox">
+			<button type="submit">Log in</button>
+			<input type="submit" name="commit" value="Log in">
+		</form>
+		<input name="remember" type="checkbox" form="login">
+		<input name="outside">
+
+		<form method="put" enctype="MULTIPART/FORM-DATA">
+			<input name="q">
+			<select name="sort"></select>
+			<textarea name="note"></textarea>
+			<input name="avatar" type="file">
+		</form>
+
+		<form name="newsletter" action="http://mail.example/subscribe" enctype="application/json">
+			<input name="email" type="email">
+		</form>
+
+		<form action="https://example.com:443/search" method="dialog"></form>
+	</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := url.Parse("https://example.com/app/account?tab=1")
+
+	want := models.FormInventory{
+		{
+			Position: 1,
+			ID:       "login",
+			Method:   "POST",
+			Action:   "https://example.com/app/session",
+			Enctype:  "application/x-www-form-urlencoded",
+			Fields: []models.FormField{
+				{Name: "email", Type: "email", Required: true, Autocomplete: "username"},
+				{Name: "password", Type: "password", Required: true},
+				{Name: "authenticity_token", Type: "hidden"},
+				{Name: "_csrf", Type: "hidden"},
+				{Name: "remember", Type: "checkbox"},
+			},
+			HasCSRFToken: true,
+			CSRFField:    "authenticity_token",
+			Autocomplete: "off",
+			HasPassword:  true,
+		},
+		{
+			Position: 2,
+			Method:   "GET",
+			Action:   "https://example.com/app/account?tab=1",
+			Enctype:  "multipart/form-data",
+			Fields: []models.FormField{
+				{Name: "q", Type: "text"},
+				{Name: "sort", Type: "select"},
+				{Name: "note", Type: "textarea"},
+				{Name: "avatar", Type: "file"},
+			},
+			Autocomplete:  "on",
+			HasFileUpload: true,
+		},
+		{
+			Position:     3,
+			Name:         "newsletter",
+			Method:       "GET",
+			Action:       "http://mail.example/subscribe",
+			Enctype:      "application/x-www-form-urlencoded",
+			Fields:       []models.FormField{{Name: "email", Type: "email"}},
+			Autocomplete: "on",
+			CrossOrigin:  true,
+			Insecure:     true,
+		},
+		{
+			Position:     4,
+			Method:       "DIALOG",
+			Action:       "https://example.com:443/search",
+			Enctype:      "application/x-www-form-urlencoded",
+			Fields:       []models.FormField{},
+			Autocomplete: "on",
+		},
+	}
+
+	got := AnalyzeForms(doc, page)
+	if len(got) != len(want) {
+		t.Fatalf("got %d forms, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("form %d = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "https://example.com/a", b: "https://EXAMPLE.com:443/b", want: true},
+		{a: "http://example.com/", b: "http://example.com:80/", want: true},
+		{a: "https://example.com/", b: "http://example.com/"},
+		{a: "https://example.com/", b: "https://example.com:8443/"},
+		{a: "https://example.com/", b: "https://www.example.com/"},
+	}
+
+	for _, tt := range tests {
+		a, _ := url.Parse(tt.a)
+		b, _ := url.Parse(tt.b)
+		if got := sameOrigin(a, b); got != tt.want {
+			t.Errorf("sameOrigin(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// WriteGraphML writes a link graph in the GraphML format
+func WriteGraphML(w io.Writer, nodes []models.GraphNode, edges []models.GraphEdge) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	for _, key := range []struct{ id, target, name, kind string }{
+		{"url", "node", "url", "string"},
+		{"crawled", "node", "crawled", "boolean"},
+		{"in_degree", "node", "in_degree", "int"},
+		{"out_degree", "node", "out_degree", "int"},
+		{"depth", "node", "depth", "int"},
+		{"pagerank", "node", "pagerank", "double"},
+		{"anchor_text", "edge", "anchor_text", "string"},
+		{"rel", "edge", "rel", "string"},
+		{"internal", "edge", "internal", "boolean"},
+		{"count", "edge", "count", "int"},
+	} {
+		fmt.Fprintf(bw, `  <key id="%s" for="%s" attr.name="%s" attr.type="%s"/>`+"\n", key.id, key.target, key.name, key.kind)
+	}
+	bw.WriteString(`  <graph id="links" edgedefault="directed">` + "\n")
+
+	ids := make(map[string]string, len(nodes))
+	for i, node := range nodes {
+		id := "n" + strconv.Itoa(i)
+		ids[node.URL] = id
+		fmt.Fprintf(bw, `    <node id="%s">`+"\n", id)
+		writeGraphMLData(bw, "url", node.URL)
+		writeGraphMLData(bw, "crawled", strconv.FormatBool(node.Crawled))
+		writeGraphMLData(bw, "in_degree", strconv.Itoa(node.InDegree))
+		writeGraphMLData(bw, "out_degree", strconv.Itoa(node.OutDegree))
+		if node.Depth != nil {
+			writeGraphMLData(bw, "depth", strconv.Itoa(*node.Depth))
+		}
+		writeGraphMLData(bw, "pagerank", strconv.FormatFloat(node.PageRank, 'f', -1, 64))
+		bw.WriteString("    </node>\n")
+	}
+
+	for i, edge := range edges {
+		fmt.Fprintf(bw, `    <edge id="e%d" source="%s" target="%s">`+"\n", i, ids[edge.Source], ids[edge.Target])
+		writeGraphMLData(bw, "anchor_text", edge.AnchorText)
+		if edge.Rel != "" {
+			writeGraphMLData(bw, "rel", edge.Rel)
+		}
+		writeGraphMLData(bw, "internal", strconv.FormatBool(edge.Internal))
+		writeGraphMLData(bw, "count", strconv.Itoa(edge.Count))
+		bw.WriteString("    </edge>\n")
+	}
+
+	bw.WriteString("  </graph>\n</graphml>\n")
+	return bw.Flush()
+}
+
+func writeGraphMLData(w *bufio.Writer, key, value string) {
+	fmt.Fprintf(w, `      <data key="%s">`, key)
+	xml.EscapeText(w, []byte(value))
+	w.WriteString("</data>\n")
+}
+
+// WriteDOT writes a link graph in the Graphviz DOT format. Pages that were
+// not crawled are drawn dashed.
+func WriteDOT(w io.Writer, name string, nodes []models.GraphNode, edges []models.GraphEdge) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(name))
+	for _, node := range nodes {
+		attrs := fmt.Sprintf("pagerank=%s", strconv.FormatFloat(node.PageRank, 'f', -1, 64))
+		if node.Depth != nil {
+			attrs += fmt.Sprintf(", depth=%d", *node.Depth)
+		}
+		if !node.Crawled {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(bw, "  %s [%s];\n", dotQuote(node.URL), attrs)
+	}
+	for _, edge := range edges {
+		attrs := []string{"label=" + dotQuote(edge.AnchorText)}
+		if edge.Rel != "" {
+			attrs = append(attrs, "rel="+dotQuote(edge.Rel))
+		}
+		if edge.Count > 1 {
+			attrs = append(attrs, "weight="+strconv.Itoa(edge.Count))
+		}
+		fmt.Fprintf(bw, "  %s -> %s [%s];\n", dotQuote(edge.Source), dotQuote(edge.Target), strings.Join(attrs, ", "))
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "").Replace(s)
+	return `"` + s + `"`
+}
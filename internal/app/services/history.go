@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FindOrCreateTrackedURL returns the tracked URL of userID for a canonical
+// URL, creating it if needed. The insert is an upsert on the unique
+// (user_id, url_hash) index, so concurrent crawls of a new URL share one
+// tracked URL. The upsert relies on MySQL's LAST_INSERT_ID and does not run
+// on other databases, such as the SQLite database of the tests.
+func FindOrCreateTrackedURL(tx *gorm.DB, userID uint64, canonicalURL string) (*models.TrackedURL, error) {
+	tracked := models.TrackedURL{UserID: userID, URL: canonicalURL, URLHash: hashURL(canonicalURL)}
+	// On a duplicate, LAST_INSERT_ID(id) reports the ID of the existing row.
+	// A plain lookup could miss a row committed by a concurrent crawl after
+	// this transaction's snapshot was taken.
+	err := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{"id": gorm.Expr("LAST_INSERT_ID(id)")}),
+	}).Create(&tracked).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracked URL: %w", err)
+	}
+
+	id := tracked.ID
+	tracked = models.TrackedURL{}
+	if err := tx.First(&tracked, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up tracked URL: %w", err)
+	}
+	return &tracked, nil
+}
+
+// RefreshTrackedURL recomputes the latest run, run count and last crawl time
+// of a tracked URL from its remaining runs. Only completed runs can become
+// the latest run, so running and failed crawls do not hide the last result.
+func RefreshTrackedURL(tx *gorm.DB, trackedURLID uint) error {
+	var runCount int64
+	if err := tx.Model(&models.CrawlResult{}).
+		Where("tracked_url_id = ?", trackedURLID).
+		Count(&runCount).Error; err != nil {
+		return fmt.Errorf("failed to count runs: %w", err)
+	}
+
+	updates := map[string]interface{}{
+		"run_count":       runCount,
+		"latest_run_id":   nil,
+		"last_crawled_at": nil,
+	}
+
+	var latest models.CrawlResult
+	err := tx.Where("tracked_url_id = ? AND status = ?", trackedURLID, models.CrawlStatusCompleted).
+		Order("id DESC").
+		First(&latest).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to find latest run: %w", err)
+	}
+	if err == nil {
+		updates["latest_run_id"] = latest.ID
+		updates["last_crawled_at"] = latest.CreatedAt
+	}
+
+	if err := tx.Model(&models.TrackedURL{}).Where("id = ?", trackedURLID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update tracked URL: %w", err)
+	}
+	return nil
+}
+
+// PruneRuns deletes the oldest unpinned runs of a tracked URL so that at most
+// keep unpinned runs remain. Pinned runs, runs still in progress and the
+// latest completed run are never pruned, so a series of failed crawls cannot
+// push the last result out of the history. A keep value of 0 or less
+// disables pruning.
+func PruneRuns(tx *gorm.DB, trackedURLID uint, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	var runs []models.CrawlResult
+	if err := tx.Select("id", "status").
+		Where("tracked_url_id = ? AND pinned = ?", trackedURLID, false).
+		Order("id DESC").
+		Find(&runs).Error; err != nil {
+		return fmt.Errorf("failed to find stale runs: %w", err)
+	}
+	if len(runs) <= keep {
+		return nil
+	}
+
+	var latestIDs []uint
+	if err := tx.Model(&models.CrawlResult{}).
+		Where("tracked_url_id = ? AND status = ?", trackedURLID, models.CrawlStatusCompleted).
+		Order("id DESC").
+		Limit(1).
+		Pluck("id", &latestIDs).Error; err != nil {
+		return fmt.Errorf("failed to find latest run: %w", err)
+	}
+
+	staleIDs := make([]uint, 0, len(runs)-keep)
+	for _, run := range runs[keep:] {
+		if run.Status == models.CrawlStatusRunning || (len(latestIDs) > 0 && run.ID == latestIDs[0]) {
+			continue
+		}
+		staleIDs = append(staleIDs, run.ID)
+	}
+	if len(staleIDs) == 0 {
+		return nil
+	}
+
+	if err := DeleteLinkEdges(tx, staleIDs); err != nil {
+		return fmt.Errorf("failed to prune link edges: %w", err)
+	}
+	if err := tx.Where("id IN ?", staleIDs).Delete(&models.CrawlResult{}).Error; err != nil {
+		return fmt.Errorf("failed to prune runs: %w", err)
+	}
+	return nil
+}
+
+// LatestRunIDs is a subquery selecting the latest run of every URL tracked
+// by userID
+func LatestRunIDs(db *gorm.DB, userID interface{}) *gorm.DB {
+	return db.Model(&models.TrackedURL{}).
+		Select("latest_run_id").
+		Where("user_id = ? AND latest_run_id IS NOT NULL", userID)
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+func TestPruneRuns(t *testing.T) {
+	db := newTestDB(t, &models.TrackedURL{}, &models.CrawlResult{}, &models.LinkEdge{})
+
+	tracked := models.TrackedURL{UserID: 1, URL: "https://example.com/", URLHash: hashURL("https://example.com/")}
+	if err := db.Create(&tracked).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// One pinned run, the last completed run, a crawl still in progress and
+	// then only failures
+	statuses := []string{
+		models.CrawlStatusCompleted,
+		models.CrawlStatusCompleted,
+		models.CrawlStatusRunning,
+		models.CrawlStatusFailed,
+		models.CrawlStatusFailed,
+		models.CrawlStatusFailed,
+		models.CrawlStatusFailed,
+	}
+	ids := make([]uint, len(statuses))
+	for i, status := range statuses {
+		run := models.CrawlResult{
+			UserID:       1,
+			TrackedURLID: tracked.ID,
+			URL:          tracked.URL,
+			Status:       status,
+			Pinned:       i == 0,
+		}
+		if err := db.Create(&run).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = run.ID
+	}
+
+	if err := PruneRuns(db, tracked.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := RefreshTrackedURL(db, tracked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var remaining []uint
+	if err := db.Model(&models.CrawlResult{}).Where("tracked_url_id = ?", tracked.ID).Order("id").Pluck("id", &remaining).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []uint{ids[0], ids[1], ids[2], ids[5], ids[6]}
+	if !reflect.DeepEqual(remaining, want) {
+		t.Errorf("remaining runs = %v, want %v", remaining, want)
+	}
+
+	if err := db.First(&tracked, tracked.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case tracked.LatestRunID == nil:
+		t.Errorf("latest run = none, want %d", ids[1])
+	case *tracked.LatestRunID != ids[1]:
+		t.Errorf("latest run = %d, want %d", *tracked.LatestRunID, ids[1])
+	}
+}
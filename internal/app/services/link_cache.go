@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// linkCacheSweepInterval is the number of stored outcomes between sweeps of
+// expired entries
+const linkCacheSweepInterval = 1000
+
+type linkCacheEntry struct {
+	statusCode int
+	err        string
+	expiresAt  time.Time
+}
+
+// LinkStatusCache shares link check outcomes across crawls, keyed by
+// canonical URL. Successful and failed checks expire after their own TTL;
+// a TTL of 0 disables caching of that outcome. Entries live in process and,
+// when the cache has a database, in a table shared by every instance.
+type LinkStatusCache struct {
+	db         *gorm.DB
+	urls       *URLCanonicalizer
+	successTTL time.Duration
+	failureTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]linkCacheEntry
+	stored  int
+}
+
+// NewLinkStatusCache creates a link status cache keyed by the URLs of urls.
+// db may be nil to keep the cache in process only.
+func NewLinkStatusCache(db *gorm.DB, urls *URLCanonicalizer, successTTL, failureTTL time.Duration) *LinkStatusCache {
+	return &LinkStatusCache{
+		db:         db,
+		urls:       urls,
+		successTTL: successTTL,
+		failureTTL: failureTTL,
+		entries:    make(map[string]linkCacheEntry),
+	}
+}
+
+// Check returns the status of target, from the cache unless fresh is set.
+// Links are checked with a HEAD request sent by client and the fresh
+// outcomes are stored for later crawls. A nil cache checks every link.
+func (c *LinkStatusCache) Check(ctx context.Context, client *http.Client, target *url.URL, fresh bool) models.LinkStatus {
+	var key string
+	if c != nil {
+		key = c.urls.LinkKey(target)
+	}
+	if c != nil && !fresh {
+		if entry, ok := c.lookup(key); ok {
+			return models.LinkStatus{StatusCode: entry.statusCode, Error: entry.err, Cached: true}
+		}
+	}
+
+	var status models.LinkStatus
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target.String(), nil)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		resp.Body.Close()
+		status.StatusCode = resp.StatusCode
+	}
+
+	if c != nil {
+		c.store(key, status)
+	}
+	return status
+}
+
+// lookup returns the unexpired entry for key, loading it from the database
+// on a miss in process
+func (c *LinkStatusCache) lookup(key string) (linkCacheEntry, bool) {
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry, true
+	}
+	if c.db == nil {
+		return linkCacheEntry{}, false
+	}
+
+	var cached models.CachedLinkStatus
+	err := c.db.Where("url_hash = ? AND expires_at > ?", hashURL(key), now).
+		Limit(1).Find(&cached).Error
+	if err != nil {
+		logger.Warn("Failed to read link status cache: %v", err)
+		return linkCacheEntry{}, false
+	}
+	if cached.ID == 0 {
+		return linkCacheEntry{}, false
+	}
+
+	entry = linkCacheEntry{statusCode: cached.StatusCode, err: cached.Error, expiresAt: cached.ExpiresAt}
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+	return entry, true
+}
+
+// store caches a fresh outcome for its TTL
+func (c *LinkStatusCache) store(key string, status models.LinkStatus) {
+	ttl := c.successTTL
+	if !status.Accessible() {
+		ttl = c.failureTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	entry := linkCacheEntry{statusCode: status.StatusCode, err: status.Error, expiresAt: now.Add(ttl)}
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.stored++
+	sweep := c.stored%linkCacheSweepInterval == 0
+	if sweep {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	if c.db == nil {
+		return
+	}
+	err := c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "url_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status_code", "error", "checked_at", "expires_at"}),
+	}).Create(&models.CachedLinkStatus{
+		URLHash:    hashURL(key),
+		URL:        truncateRunes(key, 2000),
+		StatusCode: status.StatusCode,
+		Error:      status.Error,
+		CheckedAt:  now,
+		ExpiresAt:  entry.expiresAt,
+	}).Error
+	if err != nil {
+		logger.Warn("Failed to write link status cache: %v", err)
+		return
+	}
+	if sweep {
+		if err := c.db.Where("expires_at <= ?", now).Delete(&models.CachedLinkStatus{}).Error; err != nil {
+			logger.Warn("Failed to purge link status cache: %v", err)
+		}
+	}
+}
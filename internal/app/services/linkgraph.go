@@ -0,0 +1,387 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxAnchorTextLength matches the size of the anchor_text column
+	maxAnchorTextLength = 500
+	pageRankDamping     = 0.85
+	pageRankIterations  = 100
+	pageRankTolerance   = 1e-9
+)
+
+// ErrStartNotInGraph is returned when the requested start URL is not a page
+// of the site's link graph
+var ErrStartNotInGraph = errors.New("start URL is not part of the link graph")
+
+// NormalizeLinkURL returns the form of u used to compare links within a
+// page and for links without a canonical form: lower case scheme and host,
+// no default port, no fragment and "/" for an empty path
+func NormalizeLinkURL(u *url.URL) string {
+	n := *u
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = strings.ToLower(n.Host)
+	if host, port, err := net.SplitHostPort(n.Host); err == nil {
+		if (n.Scheme == "http" && port == "80") || (n.Scheme == "https" && port == "443") {
+			n.Host = host
+		}
+	}
+	n.Fragment = ""
+	n.RawFragment = ""
+	if n.Path == "" && n.Opaque == "" {
+		n.Path = "/"
+	}
+	return n.String()
+}
+
+// ExtractLinkEdges returns the http(s) links of a page as graph edges,
+// resolved against the page URL or its <base href>. The edges start at
+// source, the URL the run is stored under, which is not the page URL when
+// the fetch was redirected. Both ends are keyed by urls, the canonicalizer
+// tracked URLs are stored with.
+func ExtractLinkEdges(doc *goquery.Document, page *url.URL, source string, urls *URLCanonicalizer) []models.LinkEdge {
+	base := documentBase(doc, page)
+	sourceKey := linkKey(urls, source)
+	sourceHost := strings.ToLower(page.Hostname())
+
+	var edges []models.LinkEdge
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href := strings.TrimSpace(s.AttrOr("href", ""))
+		if href == "" || strings.HasPrefix(href, "#") {
+			return
+		}
+		target, err := base.Parse(href)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return
+		}
+
+		anchor := strings.Join(strings.Fields(s.Text()), " ")
+		if anchor == "" {
+			anchor = strings.TrimSpace(s.Find("img[alt]").First().AttrOr("alt", ""))
+		}
+
+		targetHost := strings.ToLower(target.Hostname())
+		edges = append(edges, models.LinkEdge{
+			SourceURL:  sourceKey,
+			TargetURL:  urls.LinkKey(target),
+			TargetHost: targetHost,
+			AnchorText: truncateRunes(anchor, maxAnchorTextLength),
+			Rel:        strings.ToLower(strings.Join(strings.Fields(s.AttrOr("rel", "")), " ")),
+			Internal:   targetHost == sourceHost,
+		})
+	})
+	return edges
+}
+
+// documentBase returns the URL relative links of a page resolve against: its
+// <base href> if it has a valid one, the page URL otherwise
+func documentBase(doc *goquery.Document, page *url.URL) *url.URL {
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if resolved, err := page.Parse(strings.TrimSpace(href)); err == nil {
+			return resolved
+		}
+	}
+	return page
+}
+
+// linkKey returns the link key of a stored URL, or the URL itself if it
+// cannot be parsed
+func linkKey(urls *URLCanonicalizer, rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return urls.LinkKey(parsed)
+}
+
+// DeleteLinkEdges deletes the edges of the given crawl runs. crawlIDs is a
+// slice of IDs or a subquery selecting them.
+func DeleteLinkEdges(tx *gorm.DB, crawlIDs interface{}) error {
+	return tx.Where("crawl_id IN (?)", crawlIDs).Delete(&models.LinkEdge{}).Error
+}
+
+// BuildLinkGraph builds the link graph of host from its crawled pages and
+// their edges and computes its analytics. Click depth is measured from
+// start, or from the site root (or the shallowest crawled page) when start
+// is empty. Page, edge and start URLs are keyed by urls, so edges stored
+// under other canonicalization settings still meet their pages.
+func BuildLinkGraph(host string, pages []models.CrawlResult, edges []models.LinkEdge, start string, urls *URLCanonicalizer) (*models.LinkGraph, error) {
+	graph := &models.LinkGraph{
+		Host:        host,
+		Nodes:       make([]models.GraphNode, 0),
+		Edges:       make([]models.GraphEdge, 0),
+		Orphans:     make([]string, 0),
+		NoOutlinks:  make([]string, 0),
+		Unreachable: make([]string, 0),
+	}
+
+	index := make(map[string]int)
+	addNode := func(u string) int {
+		if i, ok := index[u]; ok {
+			return i
+		}
+		index[u] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, models.GraphNode{URL: u})
+		return index[u]
+	}
+
+	for _, page := range pages {
+		parsed, err := url.Parse(page.URL)
+		if err != nil {
+			continue
+		}
+		i := addNode(urls.LinkKey(parsed))
+		if !graph.Nodes[i].Crawled || page.ID > graph.Nodes[i].CrawlID {
+			graph.Nodes[i].Crawled = true
+			graph.Nodes[i].CrawlID = page.ID
+		}
+	}
+	graph.Pages = len(graph.Nodes)
+
+	// Collapse repeated links between the same pages into one edge
+	type edgeKey struct{ source, target string }
+	edgeIndex := make(map[edgeKey]int)
+	for _, e := range edges {
+		e.SourceURL = linkKey(urls, e.SourceURL)
+		e.TargetURL = linkKey(urls, e.TargetURL)
+		if e.SourceURL == e.TargetURL {
+			continue
+		}
+		key := edgeKey{e.SourceURL, e.TargetURL}
+		if i, ok := edgeIndex[key]; ok {
+			graph.Edges[i].Count++
+			if graph.Edges[i].AnchorText == "" {
+				graph.Edges[i].AnchorText = e.AnchorText
+			}
+			continue
+		}
+		edgeIndex[key] = len(graph.Edges)
+		graph.Edges = append(graph.Edges, models.GraphEdge{
+			Source:     e.SourceURL,
+			Target:     e.TargetURL,
+			AnchorText: e.AnchorText,
+			Rel:        e.Rel,
+			Internal:   e.Internal,
+			Count:      1,
+		})
+	}
+
+	adjacency := make(map[int][]int)
+	for _, e := range graph.Edges {
+		src, ok := index[e.Source]
+		if !ok {
+			continue
+		}
+		graph.Nodes[src].OutDegree++
+		if !e.Internal {
+			graph.Nodes[src].ExternalOutDegree++
+			continue
+		}
+		graph.Nodes[src].InternalOutDegree++
+		dst := addNode(e.Target)
+		graph.Nodes[dst].InDegree++
+		adjacency[src] = append(adjacency[src], dst)
+	}
+
+	startIndex, err := pickStartNode(graph, index, start, urls)
+	if err != nil {
+		return nil, err
+	}
+	if startIndex >= 0 {
+		graph.StartURL = graph.Nodes[startIndex].URL
+		graph.MaxDepth = computeClickDepth(graph.Nodes, adjacency, startIndex)
+	}
+
+	computePageRank(graph.Nodes, adjacency)
+
+	for i, node := range graph.Nodes {
+		if !node.Crawled {
+			continue
+		}
+		if node.InDegree == 0 && i != startIndex {
+			graph.Orphans = append(graph.Orphans, node.URL)
+		}
+		if node.OutDegree == 0 {
+			graph.NoOutlinks = append(graph.NoOutlinks, node.URL)
+		}
+		if node.Depth == nil {
+			graph.Unreachable = append(graph.Unreachable, node.URL)
+		}
+	}
+
+	sort.SliceStable(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].PageRank != graph.Nodes[j].PageRank {
+			return graph.Nodes[i].PageRank > graph.Nodes[j].PageRank
+		}
+		return graph.Nodes[i].URL < graph.Nodes[j].URL
+	})
+	return graph, nil
+}
+
+// ExportLinkGraph returns the nodes and edges to export. External links and
+// their targets are only included when includeExternal is set.
+func ExportLinkGraph(graph *models.LinkGraph, includeExternal bool) ([]models.GraphNode, []models.GraphEdge) {
+	nodes := append([]models.GraphNode(nil), graph.Nodes...)
+	edges := make([]models.GraphEdge, 0, len(graph.Edges))
+	seen := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		seen[node.URL] = true
+	}
+
+	for _, e := range graph.Edges {
+		if !e.Internal && !includeExternal {
+			continue
+		}
+		if !seen[e.Target] {
+			seen[e.Target] = true
+			nodes = append(nodes, models.GraphNode{URL: e.Target})
+		}
+		edges = append(edges, e)
+	}
+	return nodes, edges
+}
+
+// pickStartNode returns the index of the node click depth is measured from,
+// or -1 for an empty graph
+func pickStartNode(graph *models.LinkGraph, index map[string]int, start string, urls *URLCanonicalizer) (int, error) {
+	if start != "" {
+		parsed, err := url.Parse(start)
+		if err != nil {
+			return -1, ErrStartNotInGraph
+		}
+		i, ok := index[urls.LinkKey(parsed)]
+		if !ok {
+			return -1, ErrStartNotInGraph
+		}
+		return i, nil
+	}
+
+	for _, scheme := range []string{"https", "http"} {
+		if i, ok := index[scheme+"://"+graph.Host+"/"]; ok && graph.Nodes[i].Crawled {
+			return i, nil
+		}
+	}
+
+	best, bestDepth := -1, 0
+	for i, node := range graph.Nodes {
+		if !node.Crawled {
+			continue
+		}
+		depth := strings.Count(strings.Trim(pathOf(node.URL), "/"), "/")
+		if best < 0 || depth < bestDepth || (depth == bestDepth && len(node.URL) < len(graph.Nodes[best].URL)) {
+			best, bestDepth = i, depth
+		}
+	}
+	return best, nil
+}
+
+// computeClickDepth sets the depth of every node reachable from start by a
+// breadth-first search over internal links and returns the largest depth
+func computeClickDepth(nodes []models.GraphNode, adjacency map[int][]int, start int) int {
+	depths := make([]int, len(nodes))
+	for i := range depths {
+		depths[i] = -1
+	}
+	depths[start] = 0
+	queue := []int{start}
+	maxDepth := 0
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[current] {
+			if depths[next] >= 0 {
+				continue
+			}
+			depths[next] = depths[current] + 1
+			if depths[next] > maxDepth {
+				maxDepth = depths[next]
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	for i, depth := range depths {
+		if depth >= 0 {
+			d := depth
+			nodes[i].Depth = &d
+		}
+	}
+	return maxDepth
+}
+
+// computePageRank runs PageRank over the internal links. The rank of pages
+// without internal outlinks (including pages that were not crawled) is
+// spread evenly over all pages.
+func computePageRank(nodes []models.GraphNode, adjacency map[int][]int) {
+	n := len(nodes)
+	if n == 0 {
+		return
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	next := make([]float64, n)
+	for iter := 0; iter < pageRankIterations; iter++ {
+		dangling := 0.0
+		for i := range nodes {
+			if len(adjacency[i]) == 0 {
+				dangling += rank[i]
+			}
+		}
+
+		base := (1-pageRankDamping)/float64(n) + pageRankDamping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for src, targets := range adjacency {
+			share := pageRankDamping * rank[src] / float64(len(targets))
+			for _, dst := range targets {
+				next[dst] += share
+			}
+		}
+
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < pageRankTolerance {
+			break
+		}
+	}
+
+	for i := range nodes {
+		nodes[i].PageRank = math.Round(rank[i]*1e6) / 1e6
+	}
+}
+
+func pathOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Path
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max])
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"gorm.io/gorm"
+)
+
+// testLinkGraph returns the pages and edges of a small site:
+//
+//	/ -> /a (twice), /b, /docs and an external page
+//	/a -> /a, /b
+//	/docs -> /docs/intro, which was not crawled; /docs redirected to /docs/
+//	/orphan -> /a, with nothing linking to it
+//	/b has no outlinks
+func testLinkGraph() ([]models.CrawlResult, []models.LinkEdge) {
+	const site = "https://example.com"
+	pages := []models.CrawlResult{
+		{Model: gorm.Model{ID: 1}, URL: site + "/"},
+		{Model: gorm.Model{ID: 2}, URL: site + "/a"},
+		{Model: gorm.Model{ID: 3}, URL: site + "/b"},
+		{Model: gorm.Model{ID: 4}, URL: site + "/docs"},
+		{Model: gorm.Model{ID: 5}, URL: site + "/orphan"},
+	}
+	edge := func(source, target, anchor string) models.LinkEdge {
+		return models.LinkEdge{
+			SourceURL:  source,
+			TargetURL:  target,
+			AnchorText: anchor,
+			Internal:   strings.HasPrefix(target, site),
+		}
+	}
+	edges := []models.LinkEdge{
+		edge(site+"/", site+"/a", ""),
+		edge(site+"/", site+"/a", "A & B"),
+		edge(site+"/", site+"/b", "B"),
+		edge(site+"/", site+"/docs", "Docs"),
+		edge(site+"/", "https://other.example/", "Elsewhere"),
+		edge(site+"/a", site+"/a", "Self"),
+		edge(site+"/a", site+"/b", "B"),
+		// Stored under the run URL although the page was served from /docs/
+		edge(site+"/docs", site+"/docs/intro", "Intro"),
+		edge(site+"/orphan", site+"/a", "A"),
+	}
+	return pages, edges
+}
+
+func graphNode(graph *models.LinkGraph, u string) *models.GraphNode {
+	for i := range graph.Nodes {
+		if graph.Nodes[i].URL == u {
+			return &graph.Nodes[i]
+		}
+	}
+	return nil
+}
+
+func TestBuildLinkGraph(t *testing.T) {
+	pages, edges := testLinkGraph()
+	graph, err := BuildLinkGraph("example.com", pages, edges, "", NewURLCanonicalizer(false, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if graph.StartURL != "https://example.com/" || graph.Pages != 5 || graph.MaxDepth != 2 {
+		t.Errorf("start %s, %d pages and max depth %d, want https://example.com/, 5 and 2", graph.StartURL, graph.Pages, graph.MaxDepth)
+	}
+	if !reflect.DeepEqual(graph.Orphans, []string{"https://example.com/orphan"}) {
+		t.Errorf("orphans = %v", graph.Orphans)
+	}
+	if !reflect.DeepEqual(graph.NoOutlinks, []string{"https://example.com/b"}) {
+		t.Errorf("pages without outlinks = %v", graph.NoOutlinks)
+	}
+	if !reflect.DeepEqual(graph.Unreachable, []string{"https://example.com/orphan"}) {
+		t.Errorf("unreachable pages = %v", graph.Unreachable)
+	}
+
+	depth := func(d int) *int { return &d }
+	tests := []struct {
+		url                         string
+		crawled                     bool
+		in, out, internal, external int
+		depth                       *int
+	}{
+		{url: "https://example.com/", crawled: true, in: 0, out: 4, internal: 3, external: 1, depth: depth(0)},
+		{url: "https://example.com/a", crawled: true, in: 2, out: 1, internal: 1, depth: depth(1)},
+		{url: "https://example.com/b", crawled: true, in: 2, depth: depth(1)},
+		{url: "https://example.com/docs", crawled: true, in: 1, out: 1, internal: 1, depth: depth(1)},
+		{url: "https://example.com/docs/intro", in: 1, depth: depth(2)},
+		{url: "https://example.com/orphan", crawled: true, out: 1, internal: 1},
+	}
+	for _, tt := range tests {
+		node := graphNode(graph, tt.url)
+		if node == nil {
+			t.Errorf("node %s is missing", tt.url)
+			continue
+		}
+		if node.Crawled != tt.crawled || node.InDegree != tt.in || node.OutDegree != tt.out ||
+			node.InternalOutDegree != tt.internal || node.ExternalOutDegree != tt.external {
+			t.Errorf("node %s = %+v, want crawled %v, in %d, out %d (%d internal, %d external)",
+				tt.url, *node, tt.crawled, tt.in, tt.out, tt.internal, tt.external)
+		}
+		if !reflect.DeepEqual(node.Depth, tt.depth) {
+			t.Errorf("node %s depth = %v, want %v", tt.url, node.Depth, tt.depth)
+		}
+	}
+	if len(graph.Nodes) != len(tests) {
+		t.Errorf("got %d nodes, want %d; external targets are not nodes", len(graph.Nodes), len(tests))
+	}
+
+	// Repeated links collapse into one edge and self links are dropped
+	if len(graph.Edges) != 7 {
+		t.Errorf("got %d edges, want 7", len(graph.Edges))
+	}
+	for _, e := range graph.Edges {
+		if e.Source == e.Target {
+			t.Errorf("self link %s kept", e.Source)
+		}
+		if e.Target == "https://example.com/a" && e.Source == "https://example.com/" &&
+			(e.Count != 2 || e.AnchorText != "A & B") {
+			t.Errorf("collapsed edge = %+v, want count 2 and the first anchor text", e)
+		}
+	}
+
+	// PageRank is a distribution, highest for the most linked page
+	sum := 0.0
+	for i, node := range graph.Nodes {
+		sum += node.PageRank
+		if i > 0 && node.PageRank > graph.Nodes[i-1].PageRank {
+			t.Errorf("nodes are not sorted by PageRank")
+		}
+	}
+	if math.Abs(sum-1) > 1e-4 {
+		t.Errorf("PageRank sums to %v, want 1", sum)
+	}
+	if graph.Nodes[0].URL != "https://example.com/b" {
+		t.Errorf("highest ranked page = %s, want https://example.com/b", graph.Nodes[0].URL)
+	}
+	if a, orphan := graphNode(graph, "https://example.com/a"), graphNode(graph, "https://example.com/orphan"); a.PageRank <= orphan.PageRank {
+		t.Errorf("PageRank of /a (%v) is not above the orphan's (%v)", a.PageRank, orphan.PageRank)
+	}
+}
+
+func TestBuildLinkGraphStart(t *testing.T) {
+	pages, edges := testLinkGraph()
+	tests := []struct {
+		name      string
+		pages     []models.CrawlResult
+		start     string
+		wantStart string
+		wantErr   error
+	}{
+		{name: "site root", pages: pages, wantStart: "https://example.com/"},
+		{name: "explicit", pages: pages, start: "https://example.com/orphan", wantStart: "https://example.com/orphan"},
+		{name: "canonicalized", pages: pages, start: "HTTPS://Example.com:443/a#top", wantStart: "https://example.com/a"},
+		{name: "shallowest page without a root", pages: pages[1:], wantStart: "https://example.com/a"},
+		{name: "not in graph", pages: pages, start: "https://example.com/missing", wantErr: ErrStartNotInGraph},
+		{name: "unparseable", pages: pages, start: "https://example.com/%zz", wantErr: ErrStartNotInGraph},
+		{name: "empty graph", wantStart: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := BuildLinkGraph("example.com", tt.pages, edges, tt.start, NewURLCanonicalizer(false, nil))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && graph.StartURL != tt.wantStart {
+				t.Errorf("start = %s, want %s", graph.StartURL, tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestExportLinkGraph(t *testing.T) {
+	pages, edges := testLinkGraph()
+	graph, err := BuildLinkGraph("example.com", pages, edges, "", NewURLCanonicalizer(false, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	internalNodes, internalEdges := ExportLinkGraph(graph, false)
+	allNodes, allEdges := ExportLinkGraph(graph, true)
+	if len(internalNodes) != 6 || len(internalEdges) != 6 {
+		t.Errorf("internal export has %d nodes and %d edges, want 6 and 6", len(internalNodes), len(internalEdges))
+	}
+	if len(allNodes) != 7 || len(allEdges) != 7 || allNodes[6].URL != "https://other.example/" {
+		t.Errorf("full export has %d nodes and %d edges, want 7 and 7 with the external target last", len(allNodes), len(allEdges))
+	}
+
+	t.Run("graphml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteGraphML(&buf, allNodes, allEdges); err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			Graph struct {
+				Nodes []struct {
+					ID string `xml:"id,attr"`
+				} `xml:"node"`
+				Edges []struct {
+					Source string `xml:"source,attr"`
+					Target string `xml:"target,attr"`
+					Data   []struct {
+						Key   string `xml:"key,attr"`
+						Value string `xml:",chardata"`
+					} `xml:"data"`
+				} `xml:"edge"`
+			} `xml:"graph"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("invalid GraphML: %v", err)
+		}
+		if len(doc.Graph.Nodes) != len(allNodes) || len(doc.Graph.Edges) != len(allEdges) {
+			t.Fatalf("GraphML has %d nodes and %d edges, want %d and %d",
+				len(doc.Graph.Nodes), len(doc.Graph.Edges), len(allNodes), len(allEdges))
+		}
+		anchors := make([]string, 0)
+		for _, e := range doc.Graph.Edges {
+			if e.Source == "" || e.Target == "" {
+				t.Errorf("edge %+v refers to an unknown node", e)
+			}
+			for _, d := range e.Data {
+				if d.Key == "anchor_text" {
+					anchors = append(anchors, d.Value)
+				}
+			}
+		}
+		if !strings.Contains(strings.Join(anchors, "\n"), "A & B") {
+			t.Errorf("anchor texts %q lost the escaped anchor", anchors)
+		}
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		var buf bytes.Buffer
+		nodes := append(internalNodes, models.GraphNode{URL: `https://example.com/"quoted"`})
+		if err := WriteDOT(&buf, "example.com", nodes, internalEdges); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		for _, want := range []string{
+			`digraph "example.com" {`,
+			`"https://example.com/" -> "https://example.com/a" [label="A & B", weight=2];`,
+			`"https://example.com/docs/intro" [pagerank=`,
+			`"https://example.com/\"quoted\"" [pagerank=0, style=dashed];`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("DOT output lacks %s:\n%s", want, out)
+			}
+		}
+		if !strings.HasSuffix(out, "}\n") {
+			t.Errorf("DOT output is not closed:\n%s", out)
+		}
+	})
+}
@@ -0,0 +1,572 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"golang.org/x/net/html"
+)
+
+// maxMarkupFindings caps the findings kept per page; the summary still
+// counts all of them
+const maxMarkupFindings = 500
+
+// charsetPrescanLimit is how far into a document a character encoding
+// declaration must appear to be honoured by the encoding prescan
+const charsetPrescanLimit = 1024
+
+// voidElements never have content or an end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "keygen": true, "link": true,
+	"meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// optionalEndTags are elements whose end tag may be omitted, so leaving them
+// open is not a conformance problem
+var optionalEndTags = map[string]bool{
+	"html": true, "head": true, "body": true, "p": true, "li": true,
+	"dt": true, "dd": true, "rt": true, "rp": true, "optgroup": true,
+	"option": true, "colgroup": true, "caption": true, "thead": true,
+	"tbody": true, "tfoot": true, "tr": true, "td": true, "th": true,
+}
+
+// paragraphClosers implicitly close an open <p> when they start
+var paragraphClosers = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"details": true, "dialog": true, "div": true, "dl": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hgroup": true, "hr": true, "main": true, "menu": true,
+	"nav": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "ul": true,
+}
+
+// obsoleteElements are elements the HTML standard lists as obsolete
+var obsoleteElements = map[string]bool{
+	"acronym": true, "applet": true, "basefont": true, "bgsound": true,
+	"big": true, "blink": true, "center": true, "dir": true, "font": true,
+	"frame": true, "frameset": true, "isindex": true, "keygen": true,
+	"listing": true, "marquee": true, "menuitem": true, "multicol": true,
+	"nextid": true, "nobr": true, "noembed": true, "noframes": true,
+	"plaintext": true, "spacer": true, "strike": true, "tt": true, "xmp": true,
+}
+
+// obsoleteAttributes maps obsolete attributes to the elements they are
+// obsolete on; a nil list means every element
+var obsoleteAttributes = map[string][]string{
+	"align":        nil,
+	"alink":        {"body"},
+	"axis":         {"td", "th"},
+	"background":   {"body", "table", "thead", "tbody", "tfoot", "tr", "td", "th"},
+	"bgcolor":      nil,
+	"border":       {"img", "object", "table"},
+	"cellpadding":  {"table"},
+	"cellspacing":  {"table"},
+	"char":         {"col", "colgroup", "tbody", "td", "tfoot", "th", "thead", "tr"},
+	"charoff":      {"col", "colgroup", "tbody", "td", "tfoot", "th", "thead", "tr"},
+	"charset":      {"a", "link", "script"},
+	"clear":        {"br"},
+	"compact":      {"dl", "ol", "ul", "menu"},
+	"frame":        {"table"},
+	"frameborder":  {"iframe"},
+	"height":       {"table", "td", "th", "tr"},
+	"hspace":       {"embed", "iframe", "img", "object"},
+	"language":     {"script"},
+	"link":         {"body"},
+	"longdesc":     {"img", "iframe"},
+	"marginheight": {"body", "iframe"},
+	"marginwidth":  {"body", "iframe"},
+	"nowrap":       {"td", "th"},
+	"profile":      {"head"},
+	"rev":          {"a", "link"},
+	"rules":        {"table"},
+	"scrolling":    {"iframe"},
+	"summary":      {"table"},
+	"text":         {"body"},
+	"valign":       nil,
+	"version":      {"html"},
+	"vlink":        {"body"},
+	"vspace":       {"embed", "iframe", "img", "object"},
+	"width":        {"col", "colgroup", "hr", "pre", "table", "td", "th"},
+}
+
+// contentTypeCharset extracts the charset of a Content-Type meta declaration
+var contentTypeCharset = regexp.MustCompile(`(?i)charset\s*=\s*["']?([^"';\s]+)`)
+
+// openElement is an element on the open element stack
+type openElement struct {
+	name   string
+	line   int
+	column int
+}
+
+// markupChecker collects conformance findings while tokenizing a page
+type markupChecker struct {
+	report models.MarkupReport
+
+	line   int
+	column int
+	// offset is the byte offset of the end of the current token
+	offset int
+
+	stack       []openElement
+	ids         map[string]int
+	titles      int
+	inTitle     bool
+	titleText   strings.Builder
+	titleLine   int
+	titleColumn int
+	charsets    int
+}
+
+// AnalyzeMarkup tokenizes an HTML document and reports duplicate IDs,
+// obsolete elements and attributes, misnested and unclosed tags, missing
+// required attributes and problems with the title and charset declaration.
+// Every finding carries the line and column of the tag it concerns.
+func AnalyzeMarkup(body []byte) models.MarkupReport {
+	m := &markupChecker{
+		report: models.MarkupReport{
+			Summary:  models.MarkupSummary{ByType: make(map[string]int)},
+			Findings: make([]models.MarkupFinding, 0),
+		},
+		line:   1,
+		column: 1,
+		ids:    make(map[string]int),
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		line, column := m.line, m.column
+		m.advance(z.Raw())
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			m.startTag(tok, tt == html.SelfClosingTagToken, line, column)
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			m.endTag(string(name), line, column)
+		case html.TextToken:
+			if m.inTitle {
+				m.titleText.Write(z.Text())
+			}
+		}
+	}
+
+	m.finish()
+	return m.report
+}
+
+// advance moves the current position past the raw bytes of a token
+func (m *markupChecker) advance(raw []byte) {
+	for _, b := range raw {
+		switch {
+		case b == '\n':
+			m.line++
+			m.column = 1
+		case b&0xC0 != 0x80:
+			// Count runes rather than bytes by skipping UTF-8 continuation bytes
+			m.column++
+		}
+	}
+	m.offset += len(raw)
+}
+
+// add records a finding, keeping at most maxMarkupFindings of them
+func (m *markupChecker) add(finding models.MarkupFinding) {
+	summary := &m.report.Summary
+	summary.ByType[finding.Type]++
+	if finding.Severity == models.MarkupSeverityError {
+		summary.Errors++
+	} else {
+		summary.Warnings++
+	}
+
+	if len(m.report.Findings) >= maxMarkupFindings {
+		summary.Truncated = true
+		return
+	}
+	m.report.Findings = append(m.report.Findings, finding)
+}
+
+// inForeignContent reports whether an SVG or MathML element is open, where
+// the HTML element rules do not apply
+func (m *markupChecker) inForeignContent() bool {
+	for _, el := range m.stack {
+		if el.name == "svg" || el.name == "math" {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *markupChecker) startTag(tok html.Token, selfClosing bool, line, column int) {
+	name := tok.Data
+	foreign := m.inForeignContent()
+
+	m.checkAttributes(tok, foreign, line, column)
+	if !foreign {
+		m.checkElement(tok, line, column)
+	}
+
+	// Self-closing syntax is only meaningful in SVG and MathML
+	if selfClosing && (foreign || name == "svg" || name == "math") {
+		return
+	}
+	if voidElements[name] {
+		return
+	}
+
+	if !foreign {
+		m.closeImplied(name)
+	}
+	m.stack = append(m.stack, openElement{name: name, line: line, column: column})
+
+	if name == "title" && !foreign {
+		m.inTitle = true
+		m.titleText.Reset()
+		m.titleLine, m.titleColumn = line, column
+	}
+}
+
+// closeImplied pops the elements whose end tag is implied by the start of
+// an element named name
+func (m *markupChecker) closeImplied(name string) {
+	var closes []string
+	switch {
+	case name == "li":
+		closes = []string{"li"}
+	case name == "dt" || name == "dd":
+		closes = []string{"dt", "dd"}
+	case name == "option":
+		closes = []string{"option"}
+	case name == "optgroup":
+		closes = []string{"option", "optgroup"}
+	case name == "tr":
+		closes = []string{"td", "th", "tr"}
+	case name == "td" || name == "th":
+		closes = []string{"td", "th"}
+	case name == "thead" || name == "tbody" || name == "tfoot":
+		closes = []string{"td", "th", "tr", "thead", "tbody", "tfoot"}
+	case name == "rt" || name == "rp":
+		closes = []string{"rt", "rp"}
+	case name == "body":
+		closes = []string{"head"}
+	}
+	if paragraphClosers[name] {
+		closes = append(closes, "p")
+	}
+
+	for len(m.stack) > 0 {
+		top := m.stack[len(m.stack)-1].name
+		if !containsString(closes, top) {
+			return
+		}
+		m.stack = m.stack[:len(m.stack)-1]
+	}
+}
+
+func (m *markupChecker) endTag(name string, line, column int) {
+	if name == "title" && m.inTitle {
+		m.inTitle = false
+		m.titles++
+		if m.titles > 1 {
+			m.add(models.MarkupFinding{
+				Type:     models.MarkupMultipleTitles,
+				Severity: models.MarkupSeverityError,
+				Message:  "document has more than one <title> element",
+				Element:  "title",
+				Line:     m.titleLine,
+				Column:   m.titleColumn,
+			})
+		} else if strings.TrimSpace(m.titleText.String()) == "" {
+			m.add(models.MarkupFinding{
+				Type:     models.MarkupEmptyTitle,
+				Severity: models.MarkupSeverityError,
+				Message:  "<title> element is empty",
+				Element:  "title",
+				Line:     m.titleLine,
+				Column:   m.titleColumn,
+			})
+		}
+	}
+
+	idx := -1
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		if m.stack[i].name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		// End tags of omitted html, head and body elements are harmless
+		if name == "html" || name == "head" || name == "body" {
+			return
+		}
+		m.add(models.MarkupFinding{
+			Type:     models.MarkupStrayEndTag,
+			Severity: models.MarkupSeverityError,
+			Message:  fmt.Sprintf("end tag </%s> has no matching start tag", name),
+			Element:  name,
+			Line:     line,
+			Column:   column,
+		})
+		return
+	}
+
+	for i := len(m.stack) - 1; i > idx; i-- {
+		open := m.stack[i]
+		if optionalEndTags[open.name] {
+			continue
+		}
+		// Elements still open when the body or document ends are unclosed
+		// rather than misnested
+		if name == "body" || name == "html" {
+			m.unclosed(open)
+			continue
+		}
+		m.add(models.MarkupFinding{
+			Type:     models.MarkupMisnestedTag,
+			Severity: models.MarkupSeverityError,
+			Message: fmt.Sprintf("</%s> closes <%s> while <%s> opened at line %d, column %d is still open",
+				name, name, open.name, open.line, open.column),
+			Element: open.name,
+			Line:    line,
+			Column:  column,
+		})
+	}
+	m.stack = m.stack[:idx]
+}
+
+// checkAttributes reports duplicate and obsolete attributes and duplicate IDs
+func (m *markupChecker) checkAttributes(tok html.Token, foreign bool, line, column int) {
+	seen := make(map[string]bool, len(tok.Attr))
+	for _, attr := range tok.Attr {
+		key := attr.Key
+		if attr.Namespace != "" {
+			key = attr.Namespace + ":" + key
+		}
+		if seen[key] {
+			m.add(models.MarkupFinding{
+				Type:     models.MarkupDuplicateAttribute,
+				Severity: models.MarkupSeverityError,
+				Message:  fmt.Sprintf("<%s> has a duplicate %q attribute", tok.Data, key),
+				Element:  tok.Data,
+				Line:     line,
+				Column:   column,
+			})
+			continue
+		}
+		seen[key] = true
+
+		if key == "id" && attr.Val != "" {
+			if first, ok := m.ids[attr.Val]; ok {
+				m.add(models.MarkupFinding{
+					Type:     models.MarkupDuplicateID,
+					Severity: models.MarkupSeverityError,
+					Message:  fmt.Sprintf("id %q is already used on line %d", attr.Val, first),
+					Element:  tok.Data,
+					Line:     line,
+					Column:   column,
+				})
+			} else {
+				m.ids[attr.Val] = line
+			}
+		}
+
+		if foreign {
+			continue
+		}
+		elements, ok := obsoleteAttributes[key]
+		if ok && (elements == nil || containsString(elements, tok.Data)) {
+			m.add(models.MarkupFinding{
+				Type:     models.MarkupObsoleteAttribute,
+				Severity: models.MarkupSeverityWarning,
+				Message:  fmt.Sprintf("the %q attribute on <%s> is obsolete", key, tok.Data),
+				Element:  tok.Data,
+				Line:     line,
+				Column:   column,
+			})
+		}
+	}
+}
+
+// checkElement reports obsolete elements, missing required attributes and
+// charset declarations
+func (m *markupChecker) checkElement(tok html.Token, line, column int) {
+	name := tok.Data
+	if obsoleteElements[name] {
+		m.add(models.MarkupFinding{
+			Type:     models.MarkupObsoleteElement,
+			Severity: models.MarkupSeverityWarning,
+			Message:  fmt.Sprintf("<%s> is obsolete", name),
+			Element:  name,
+			Line:     line,
+			Column:   column,
+		})
+	}
+
+	missing := func(attrs ...string) {
+		for _, attr := range attrs {
+			if _, ok := tokenAttr(tok, attr); ok {
+				return
+			}
+		}
+		m.add(models.MarkupFinding{
+			Type:     models.MarkupMissingAttribute,
+			Severity: models.MarkupSeverityError,
+			Message:  fmt.Sprintf("<%s> is missing the required %s attribute", name, strings.Join(attrs, " or ")),
+			Element:  name,
+			Line:     line,
+			Column:   column,
+		})
+	}
+
+	switch name {
+	case "img":
+		missing("src")
+		missing("alt")
+	case "area":
+		if _, ok := tokenAttr(tok, "href"); ok {
+			missing("alt")
+		}
+	case "input":
+		if typ, _ := tokenAttr(tok, "type"); strings.EqualFold(typ, "image") {
+			missing("alt")
+		}
+	case "link":
+		missing("href")
+		if _, ok := tokenAttr(tok, "itemprop"); !ok {
+			missing("rel")
+		}
+	case "base":
+		missing("href", "target")
+	case "optgroup":
+		missing("label")
+	case "track":
+		missing("src")
+	case "object":
+		missing("data", "type")
+	case "meta":
+		m.checkMeta(tok, line, column)
+	}
+}
+
+// checkMeta reports a meta element without any of its possible purposes and
+// charset declarations that are repeated, late or not UTF-8
+func (m *markupChecker) checkMeta(tok html.Token, line, column int) {
+	charset, ok := tokenAttr(tok, "charset")
+	if !ok {
+		equiv, _ := tokenAttr(tok, "http-equiv")
+		content, _ := tokenAttr(tok, "content")
+		if strings.EqualFold(equiv, "content-type") {
+			if match := contentTypeCharset.FindStringSubmatch(content); match != nil {
+				charset, ok = match[1], true
+			}
+		}
+	}
+	if !ok {
+		for _, attr := range []string{"name", "http-equiv", "itemprop", "property"} {
+			if _, has := tokenAttr(tok, attr); has {
+				return
+			}
+		}
+		m.add(models.MarkupFinding{
+			Type:     models.MarkupMissingAttribute,
+			Severity: models.MarkupSeverityError,
+			Message:  "<meta> is missing a name, http-equiv, charset or itemprop attribute",
+			Element:  "meta",
+			Line:     line,
+			Column:   column,
+		})
+		return
+	}
+
+	m.charsets++
+	finding := models.MarkupFinding{Element: "meta", Line: line, Column: column}
+	switch {
+	case m.charsets > 1:
+		finding.Type = models.MarkupMultipleCharsets
+		finding.Severity = models.MarkupSeverityError
+		finding.Message = "document declares its character encoding more than once"
+	case m.offset > charsetPrescanLimit:
+		finding.Type = models.MarkupLateCharset
+		finding.Severity = models.MarkupSeverityError
+		finding.Message = fmt.Sprintf("character encoding declaration is not within the first %d bytes", charsetPrescanLimit)
+	default:
+		if name := strings.ToLower(strings.TrimSpace(charset)); name != "utf-8" && name != "utf8" {
+			finding.Type = models.MarkupNonUTF8Charset
+			finding.Severity = models.MarkupSeverityWarning
+			finding.Message = fmt.Sprintf("character encoding %q is not UTF-8", charset)
+		}
+	}
+	if finding.Type != "" {
+		m.add(finding)
+	}
+}
+
+// finish reports unclosed elements and document level title and charset
+// problems once the whole document has been tokenized
+func (m *markupChecker) finish() {
+	for _, open := range m.stack {
+		if !optionalEndTags[open.name] {
+			m.unclosed(open)
+		}
+	}
+
+	if m.titles == 0 && !m.inTitle {
+		m.add(models.MarkupFinding{
+			Type:     models.MarkupMissingTitle,
+			Severity: models.MarkupSeverityError,
+			Message:  "document has no <title> element",
+			Element:  "title",
+		})
+	}
+	if m.charsets == 0 {
+		m.add(models.MarkupFinding{
+			Type:     models.MarkupMissingCharset,
+			Severity: models.MarkupSeverityWarning,
+			Message:  "document does not declare its character encoding",
+			Element:  "meta",
+		})
+	}
+}
+
+// unclosed reports an element whose end tag is missing
+func (m *markupChecker) unclosed(open openElement) {
+	m.add(models.MarkupFinding{
+		Type:     models.MarkupUnclosedTag,
+		Severity: models.MarkupSeverityError,
+		Message:  fmt.Sprintf("<%s> is never closed", open.name),
+		Element:  open.name,
+		Line:     open.line,
+		Column:   open.column,
+	})
+}
+
+// tokenAttr returns the value of the attribute key of a token
+func tokenAttr(tok html.Token, key string) (string, bool) {
+	for _, attr := range tok.Attr {
+		if attr.Namespace == "" && attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// markupPage wraps body content in a document that is conformant by itself,
+// with the content starting on line 2
+func markupPage(body string) string {
+	return "<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>Test</title></head><body>\n" + body + "\n</body></html>"
+}
+
+func TestAnalyzeMarkup(t *testing.T) {
+	type finding struct {
+		Type         string
+		Line, Column int
+	}
+	tests := []struct {
+		name string
+		html string
+		want []finding
+	}{
+		{name: "conformant", html: markupPage(`<p>Hello <b>world</b></p>`)},
+		{name: "duplicate id", html: markupPage("<div id=\"a\"></div>\n  <p id=\"a\"></p>"), want: []finding{
+			{models.MarkupDuplicateID, 3, 3},
+		}},
+		{name: "duplicate attribute", html: markupPage(`<p class="a" class="b"></p>`), want: []finding{
+			{models.MarkupDuplicateAttribute, 2, 1},
+		}},
+		{name: "obsolete element and attribute", html: markupPage(`<center><table border="1" bgcolor="red"></table></center>`), want: []finding{
+			{models.MarkupObsoleteElement, 2, 1},
+			{models.MarkupObsoleteAttribute, 2, 9},
+			{models.MarkupObsoleteAttribute, 2, 9},
+		}},
+		{name: "misnested", html: markupPage(`<b><i>x</b></i>`), want: []finding{
+			{models.MarkupMisnestedTag, 2, 8},
+			{models.MarkupStrayEndTag, 2, 12},
+		}},
+		{name: "unclosed", html: markupPage(`<div><span>x</span>`), want: []finding{
+			{models.MarkupUnclosedTag, 2, 1},
+		}},
+		{name: "optional end tags", html: markupPage(`<ul><li>a<li>b</ul><p>c<div></div>`)},
+		{name: "missing attributes", html: markupPage(`<img src="a.png"><meta><input type="image">`), want: []finding{
+			{models.MarkupMissingAttribute, 2, 1},
+			{models.MarkupMissingAttribute, 2, 18},
+			{models.MarkupMissingAttribute, 2, 24},
+		}},
+		{name: "columns count runes", html: markupPage(`<p>héllo wörld</p><img src="a.png" alt="">`)},
+		{name: "columns after multibyte text", html: markupPage(`<p>héllo</p><strike>x</strike>`), want: []finding{
+			{models.MarkupObsoleteElement, 2, 13},
+		}},
+		{name: "foreign content", html: markupPage(`<svg><rect width="1" height="1"/><font/></svg>`)},
+		{
+			name: "title and charset",
+			html: "<html><head><meta charset=\"latin1\"><title> </title>\n<title>Again</title></head><body></body></html>",
+			want: []finding{
+				{models.MarkupNonUTF8Charset, 1, 13},
+				{models.MarkupEmptyTitle, 1, 36},
+				{models.MarkupMultipleTitles, 2, 1},
+			},
+		},
+		{
+			name: "late charset",
+			html: "<html><head><title>x</title><!--" + strings.Repeat("-", charsetPrescanLimit) + "--><meta charset=\"utf-8\"></head></html>",
+			want: []finding{{models.MarkupLateCharset, 1, 1060}},
+		},
+		{name: "missing title and charset", html: "<p>x</p>", want: []finding{
+			{models.MarkupMissingTitle, 0, 0},
+			{models.MarkupMissingCharset, 0, 0},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := AnalyzeMarkup([]byte(tt.html))
+			got := make([]finding, 0, len(report.Findings))
+			for _, f := range report.Findings {
+				got = append(got, finding{f.Type, f.Line, f.Column})
+			}
+			want := tt.want
+			if want == nil {
+				want = []finding{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("findings = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeMarkupSummary(t *testing.T) {
+	report := AnalyzeMarkup([]byte(markupPage(strings.Repeat("<img src=\"a.png\">\n", maxMarkupFindings+100) + "<font>x</font>")))
+
+	summary := report.Summary
+	if len(report.Findings) != maxMarkupFindings || !summary.Truncated {
+		t.Errorf("kept %d findings (truncated %v), want %d and truncated", len(report.Findings), summary.Truncated, maxMarkupFindings)
+	}
+	if summary.Errors != maxMarkupFindings+100 || summary.Warnings != 1 {
+		t.Errorf("summary counts %d errors and %d warnings, want %d and 1", summary.Errors, summary.Warnings, maxMarkupFindings+100)
+	}
+	want := map[string]int{models.MarkupMissingAttribute: maxMarkupFindings + 100, models.MarkupObsoleteElement: 1}
+	if !reflect.DeepEqual(summary.ByType, want) {
+		t.Errorf("summary by type = %v, want %v", summary.ByType, want)
+	}
+	if last := report.Findings[len(report.Findings)-1]; last.Line != maxMarkupFindings+1 {
+		t.Errorf("last kept finding is on line %d, want %d", last.Line, maxMarkupFindings+1)
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// securityHeaders are the response headers every page is expected to send
+var securityHeaders = []string{
+	"Strict-Transport-Security",
+	"Content-Security-Policy",
+	"X-Content-Type-Options",
+	"X-Frame-Options",
+	"Referrer-Policy",
+	"Permissions-Policy",
+}
+
+// ExtractMetadata reads the description, viewport and canonical URL of a
+// page and checks its response for the recommended security headers.
+// header is nil for pages without a response.
+func ExtractMetadata(doc *goquery.Document, header http.Header) models.PageMetadata {
+	metadata := models.PageMetadata{
+		SecurityHeaders: models.SecurityHeaders{
+			Present: make([]string, 0),
+			Missing: make([]string, 0),
+		},
+	}
+
+	doc.Find("meta[name]").Each(func(i int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		content, _ := s.Attr("content")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "description":
+			if metadata.Description == "" {
+				metadata.Description = strings.TrimSpace(content)
+			}
+		case "viewport":
+			if metadata.Viewport == "" {
+				metadata.Viewport = strings.TrimSpace(content)
+			}
+		}
+	})
+	doc.Find("link[href]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		rel, _ := s.Attr("rel")
+		for _, r := range strings.Fields(strings.ToLower(rel)) {
+			if r == "canonical" {
+				href, _ := s.Attr("href")
+				metadata.Canonical = strings.TrimSpace(href)
+				return false
+			}
+		}
+		return true
+	})
+
+	if header == nil {
+		return metadata
+	}
+	metadata.SecurityHeaders.Checked = true
+	for _, name := range securityHeaders {
+		present := strings.TrimSpace(header.Get(name)) != ""
+		// frame-ancestors in the Content-Security-Policy supersedes X-Frame-Options
+		if name == "X-Frame-Options" && !present {
+			present = strings.Contains(strings.ToLower(header.Get("Content-Security-Policy")), "frame-ancestors")
+		}
+		if present {
+			metadata.SecurityHeaders.Present = append(metadata.SecurityHeaders.Present, name)
+		} else {
+			metadata.SecurityHeaders.Missing = append(metadata.SecurityHeaders.Missing, name)
+		}
+	}
+	return metadata
+}
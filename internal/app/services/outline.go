@@ -0,0 +1,104 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// BuildHeadingOutline walks the h1-h6 elements of the document in order and
+// nests them into a tree, then validates the resulting structure.
+func BuildHeadingOutline(doc *goquery.Document) models.HeadingOutline {
+	outline := models.HeadingOutline{
+		Headings: make([]*models.HeadingNode, 0),
+		Issues:   make([]models.OutlineIssue, 0),
+	}
+
+	var stack []*models.HeadingNode
+	previousLevel := 0
+	h1Count := 0
+
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(i int, s *goquery.Selection) {
+		node := &models.HeadingNode{
+			Level:    headingLevel(goquery.NodeName(s)),
+			Text:     headingText(s),
+			Position: i + 1,
+		}
+
+		if node.Level == 1 {
+			h1Count++
+			if h1Count > 1 {
+				outline.Issues = append(outline.Issues, models.OutlineIssue{
+					Type:     models.OutlineIssueMultipleH1,
+					Message:  "Page has more than one h1 heading",
+					Position: node.Position,
+				})
+			}
+		}
+
+		if previousLevel > 0 && node.Level > previousLevel+1 {
+			outline.Issues = append(outline.Issues, models.OutlineIssue{
+				Type:     models.OutlineIssueSkippedLevel,
+				Message:  fmt.Sprintf("Heading level skipped from h%d to h%d", previousLevel, node.Level),
+				Position: node.Position,
+			})
+		}
+
+		if node.Text == "" {
+			outline.Issues = append(outline.Issues, models.OutlineIssue{
+				Type:     models.OutlineIssueEmptyHeading,
+				Message:  fmt.Sprintf("h%d heading has no text", node.Level),
+				Position: node.Position,
+			})
+		}
+
+		// Pop until the top of the stack is a valid parent for this heading
+		for len(stack) > 0 && stack[len(stack)-1].Level >= node.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			outline.Headings = append(outline.Headings, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, node)
+		previousLevel = node.Level
+	})
+
+	if h1Count == 0 {
+		outline.Issues = append(outline.Issues, models.OutlineIssue{
+			Type:    models.OutlineIssueMissingH1,
+			Message: "Page has no h1 heading",
+		})
+	}
+
+	return outline
+}
+
+// headingLevel converts a tag name such as "h3" to its numeric level
+func headingLevel(tag string) int {
+	if len(tag) != 2 || tag[0] != 'h' || tag[1] < '1' || tag[1] > '6' {
+		return 0
+	}
+	return int(tag[1] - '0')
+}
+
+// headingText returns the whitespace-normalized text of a heading, falling
+// back to the alt text of any images it contains.
+func headingText(s *goquery.Selection) string {
+	text := strings.Join(strings.Fields(s.Text()), " ")
+	if text != "" {
+		return text
+	}
+
+	var alts []string
+	s.Find("img[alt]").Each(func(_ int, img *goquery.Selection) {
+		if alt := strings.TrimSpace(img.AttrOr("alt", "")); alt != "" {
+			alts = append(alts, alt)
+		}
+	})
+	return strings.Join(alts, " ")
+}
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+const (
+	// robotsTTL is how long the Crawl-delay of a host is reused
+	robotsTTL = time.Hour
+	// maxRobotsSize limits how much of a robots.txt is read
+	maxRobotsSize = 512 << 10
+	robotsTimeout = 10 * time.Second
+	// hostIdleTTL is how long the state of a host without requests is kept.
+	// It outlasts every delay, so evicting a host never shortens one.
+	hostIdleTTL = robotsTTL
+)
+
+// hostUserKey is the context key of the user a request is made for
+type hostUserKey struct{}
+
+// WithHostUser marks the requests made with ctx as made for userID, so the
+// host statistics of a user only show the hosts they are crawling
+func WithHostUser(ctx context.Context, userID uint64) context.Context {
+	return context.WithValue(ctx, hostUserKey{}, userID)
+}
+
+// hostUsage counts the waiting and running requests to a host
+type hostUsage struct {
+	waiting int
+	active  int
+}
+
+// hostState tracks the outbound requests to a single host
+type hostState struct {
+	slots chan struct{}
+	mu    sync.Mutex
+	next  time.Time
+	hostUsage
+	users    map[uint64]*hostUsage
+	lastUsed time.Time
+
+	robotsDelay     time.Duration
+	robotsFetchedAt time.Time
+}
+
+// track adjusts the waiting and running request counts of the host and of
+// userID. It is called holding mu.
+func (h *hostState) track(userID uint64, waiting, active int) {
+	h.waiting += waiting
+	h.active += active
+	h.lastUsed = time.Now()
+
+	usage, ok := h.users[userID]
+	if !ok {
+		usage = &hostUsage{}
+		h.users[userID] = usage
+	}
+	usage.waiting += waiting
+	usage.active += active
+	if usage.waiting == 0 && usage.active == 0 {
+		delete(h.users, userID)
+	}
+}
+
+// idle reports whether the host has had no requests for hostIdleTTL. It is
+// called holding mu.
+func (h *hostState) idle(now time.Time) bool {
+	return h.waiting == 0 && h.active == 0 && now.Sub(h.lastUsed) > hostIdleTTL
+}
+
+// HostScheduler is the process-wide politeness gate for requests to crawled
+// sites. Every request to a host waits for one of the host's slots and at
+// least the host's delay after the previous request started. The delay is
+// the configured one, raised to the host's robots.txt Crawl-delay up to
+// maxDelay. Hosts without requests for a while are forgotten.
+type HostScheduler struct {
+	delay         time.Duration
+	maxDelay      time.Duration
+	concurrency   int
+	respectRobots bool
+	robotsClient  *http.Client
+
+	mu        sync.Mutex
+	hosts     map[string]*hostState
+	lastSweep time.Time
+}
+
+// NewHostScheduler creates a host scheduler allowing concurrency requests
+// per host at a time
+func NewHostScheduler(delay, maxDelay time.Duration, concurrency int, respectRobots bool) *HostScheduler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &HostScheduler{
+		delay:         delay,
+		maxDelay:      maxDelay,
+		concurrency:   concurrency,
+		respectRobots: respectRobots,
+		robotsClient:  &http.Client{Timeout: robotsTimeout},
+		hosts:         make(map[string]*hostState),
+	}
+}
+
+// Client returns an HTTP client whose requests, redirects included, go
+// through the scheduler. timeout bounds each request once it has been let
+// through, so time spent waiting for the host does not count against it.
+func (s *HostScheduler) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: &politeTransport{hosts: s, base: http.DefaultTransport, timeout: timeout}}
+}
+
+// Acquire blocks until a request to u may start. The returned function must
+// be called once the request has finished.
+func (s *HostScheduler) Acquire(ctx context.Context, u *url.URL) (func(), error) {
+	userID, _ := ctx.Value(hostUserKey{}).(uint64)
+	state := s.wait(strings.ToLower(u.Host), userID)
+
+	select {
+	case state.slots <- struct{}{}:
+	case <-ctx.Done():
+		state.mu.Lock()
+		state.track(userID, -1, 0)
+		state.mu.Unlock()
+		return nil, ctx.Err()
+	}
+
+	delay := s.hostDelay(ctx, u, state)
+
+	// Reserve the next start time, then sleep until it comes
+	state.mu.Lock()
+	now := time.Now()
+	start := state.next
+	if start.Before(now) {
+		start = now
+	}
+	state.next = start.Add(delay)
+	state.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(start))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		state.mu.Lock()
+		state.track(userID, -1, 0)
+		state.mu.Unlock()
+		<-state.slots
+		return nil, ctx.Err()
+	}
+
+	state.mu.Lock()
+	state.track(userID, -1, 1)
+	state.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			state.mu.Lock()
+			state.track(userID, 0, -1)
+			state.mu.Unlock()
+			<-state.slots
+		})
+	}, nil
+}
+
+// Stats returns the queue depth and delay of every host with waiting or
+// running requests made for userID, busiest first. The counts only include
+// the requests of userID.
+func (s *HostScheduler) Stats(userID uint64) []models.HostStats {
+	s.mu.Lock()
+	hosts := make(map[string]*hostState, len(s.hosts))
+	for host, state := range s.hosts {
+		hosts[host] = state
+	}
+	s.mu.Unlock()
+
+	stats := make([]models.HostStats, 0)
+	for host, state := range hosts {
+		state.mu.Lock()
+		usage, ok := state.users[userID]
+		if !ok {
+			state.mu.Unlock()
+			continue
+		}
+		stats = append(stats, models.HostStats{
+			Host:               host,
+			Waiting:            usage.waiting,
+			Active:             usage.active,
+			DelayMS:            s.effectiveDelay(state.robotsDelay).Milliseconds(),
+			RobotsCrawlDelayMS: state.robotsDelay.Milliseconds(),
+		})
+		state.mu.Unlock()
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Waiting != stats[j].Waiting {
+			return stats[i].Waiting > stats[j].Waiting
+		}
+		return stats[i].Host < stats[j].Host
+	})
+	return stats
+}
+
+// wait returns the state of host with a request of userID counted as
+// waiting. Counting it under the scheduler lock keeps the state from being
+// evicted before the request is through.
+func (s *HostScheduler) wait(host string, userID uint64) *hostState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		s.evictIdle(now)
+		s.lastSweep = now
+	}
+
+	state, ok := s.hosts[host]
+	if !ok {
+		state = &hostState{
+			slots: make(chan struct{}, s.concurrency),
+			users: make(map[uint64]*hostUsage),
+		}
+		s.hosts[host] = state
+	}
+	state.mu.Lock()
+	state.track(userID, 1, 0)
+	state.mu.Unlock()
+	return state
+}
+
+// evictIdle forgets the hosts that have been idle for hostIdleTTL. It is
+// called holding mu.
+func (s *HostScheduler) evictIdle(now time.Time) {
+	for host, state := range s.hosts {
+		state.mu.Lock()
+		idle := state.idle(now)
+		state.mu.Unlock()
+		if idle {
+			delete(s.hosts, host)
+		}
+	}
+}
+
+// hostDelay returns the delay between requests to the host, refreshing its
+// robots.txt Crawl-delay when it is stale. It is called holding a slot of
+// the host, so robots.txt is never fetched alongside other requests.
+func (s *HostScheduler) hostDelay(ctx context.Context, u *url.URL, state *hostState) time.Duration {
+	state.mu.Lock()
+	stale := s.respectRobots && time.Since(state.robotsFetchedAt) > robotsTTL
+	robotsDelay := state.robotsDelay
+	state.mu.Unlock()
+
+	if stale {
+		robotsDelay = s.fetchCrawlDelay(ctx, u)
+		state.mu.Lock()
+		state.robotsDelay = robotsDelay
+		state.robotsFetchedAt = time.Now()
+		state.mu.Unlock()
+	}
+	return s.effectiveDelay(robotsDelay)
+}
+
+func (s *HostScheduler) effectiveDelay(robotsDelay time.Duration) time.Duration {
+	if robotsDelay > s.maxDelay {
+		robotsDelay = s.maxDelay
+	}
+	if robotsDelay > s.delay {
+		return robotsDelay
+	}
+	return s.delay
+}
+
+// fetchCrawlDelay reads the Crawl-delay that robots.txt sets for all user
+// agents. A missing or unreadable robots.txt sets none.
+func (s *HostScheduler) fetchCrawlDelay(ctx context.Context, u *url.URL) time.Duration {
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return 0
+	}
+	resp, err := s.robotsClient.Do(req)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0
+	}
+	return parseCrawlDelay(io.LimitReader(resp.Body, maxRobotsSize))
+}
+
+// parseCrawlDelay returns the Crawl-delay of the "User-agent: *" group of a
+// robots.txt file, or 0 when it sets none
+func parseCrawlDelay(r io.Reader) time.Duration {
+	var delay time.Duration
+	inGroup, groupStarted := false, false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		switch field {
+		case "user-agent":
+			// Consecutive User-agent lines share the rules that follow
+			if groupStarted {
+				inGroup, groupStarted = false, false
+			}
+			if value == "*" {
+				inGroup = true
+			}
+		case "crawl-delay":
+			groupStarted = true
+			if !inGroup {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				delay = time.Duration(seconds * float64(time.Second))
+			}
+		default:
+			groupStarted = true
+		}
+	}
+	return delay
+}
+
+// politeTransport lets every request through the host scheduler before
+// sending it
+type politeTransport struct {
+	hosts   *HostScheduler
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *politeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.hosts.Acquire(req.Context(), req.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+	}
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the request timeout once the body has been read
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
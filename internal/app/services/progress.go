@@ -0,0 +1,91 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// progressRetention is how long the events of a finished crawl are kept so
+// that late or reconnecting clients can still replay them
+const progressRetention = 5 * time.Minute
+
+// ProgressHub keeps the progress events of the crawls running in this process
+// in memory and wakes up the clients streaming them
+type ProgressHub struct {
+	mu      sync.Mutex
+	streams map[uint]*progressStream
+}
+
+type progressStream struct {
+	events []models.ProgressEvent
+	done   bool
+	// changed is closed and replaced whenever an event is added
+	changed chan struct{}
+}
+
+// NewProgressHub creates an empty hub
+func NewProgressHub() *ProgressHub {
+	return &ProgressHub{streams: make(map[uint]*progressStream)}
+}
+
+// Publish appends an event to the stream of a crawl. Completed and failed
+// events finish the stream; it is dropped after a retention period.
+func (h *ProgressHub) Publish(crawlID uint, eventType string, data interface{}) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stream, ok := h.streams[crawlID]
+	if !ok {
+		stream = &progressStream{changed: make(chan struct{})}
+		h.streams[crawlID] = stream
+	}
+	if stream.done {
+		return
+	}
+
+	stream.events = append(stream.events, models.ProgressEvent{
+		ID:      len(stream.events) + 1,
+		CrawlID: crawlID,
+		Type:    eventType,
+		Time:    time.Now(),
+		Data:    data,
+	})
+	if eventType == models.ProgressCompleted || eventType == models.ProgressFailed {
+		stream.done = true
+		time.AfterFunc(progressRetention, func() {
+			h.mu.Lock()
+			delete(h.streams, crawlID)
+			h.mu.Unlock()
+		})
+	}
+
+	close(stream.changed)
+	stream.changed = make(chan struct{})
+}
+
+// Events returns the events of a crawl with an ID greater than afterID,
+// whether the stream has finished, and a channel that is closed when more
+// events arrive. found is false when this process has no stream for the
+// crawl, e.g. because it ran elsewhere or finished long ago.
+func (h *ProgressHub) Events(crawlID uint, afterID int) (events []models.ProgressEvent, done bool, changed <-chan struct{}, found bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stream, ok := h.streams[crawlID]
+	if !ok {
+		return nil, false, nil, false
+	}
+	if afterID < 0 {
+		afterID = 0
+	}
+	if afterID < len(stream.events) {
+		events = append(events, stream.events[afterID:]...)
+	}
+	return events, stream.done, stream.changed, true
+}
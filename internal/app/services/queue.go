@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+)
+
+// ErrQueueFull is returned when a crawl cannot be queued because all
+// workers are busy and the backlog is full
+var ErrQueueFull = errors.New("crawl queue is full")
+
+// CrawlJob is a crawl waiting to be run by the queue. Run, if set, is a run
+// already stored by Crawler.Begin, or by Crawler.BeginUpload with the
+// uploaded HTML in Upload; otherwise the crawl of URL starts when a worker
+// picks up the job. Done, if set, is called with the outcome once the crawl
+// has finished.
+type CrawlJob struct {
+	UserID uint64
+	URL    string
+	Run    *models.CrawlResult
+	Upload []byte
+	Done   func(*CrawlOutput, error)
+}
+
+// CrawlQueue runs crawls in the background on a fixed pool of workers
+type CrawlQueue struct {
+	crawler *Crawler
+	jobs    chan CrawlJob
+	workers int
+	wg      sync.WaitGroup
+}
+
+// NewCrawlQueue creates a queue with the given number of workers and
+// backlog size
+func NewCrawlQueue(crawler *Crawler, workers, size int) *CrawlQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	return &CrawlQueue{
+		crawler: crawler,
+		jobs:    make(chan CrawlJob, size),
+		workers: workers,
+	}
+}
+
+// Start launches the workers. They exit once ctx is cancelled.
+func (q *CrawlQueue) Start(ctx context.Context) {
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-q.jobs:
+					q.run(ctx, job)
+				}
+			}
+		}()
+	}
+}
+
+// Enqueue adds a crawl to the backlog without blocking
+func (q *CrawlQueue) Enqueue(job CrawlJob) error {
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Depth returns the number of crawls waiting for a worker
+func (q *CrawlQueue) Depth() int {
+	return len(q.jobs)
+}
+
+// Wait blocks until all workers have exited
+func (q *CrawlQueue) Wait() {
+	q.wg.Wait()
+}
+
+func (q *CrawlQueue) run(ctx context.Context, job CrawlJob) {
+	var output *CrawlOutput
+	var err error
+	switch {
+	case job.Run != nil && job.Upload != nil:
+		output, err = q.crawler.RunUpload(ctx, job.Run, job.Upload)
+	case job.Run != nil:
+		output, err = q.crawler.Run(ctx, job.Run)
+	default:
+		output, err = q.crawler.Crawl(ctx, job.UserID, job.URL, models.CrawlOptions{})
+	}
+	if err != nil {
+		logger.Warn("Queued crawl of %s failed: %v", job.URL, err)
+	}
+	if job.Done != nil {
+		job.Done(output, err)
+	}
+}
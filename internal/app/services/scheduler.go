@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"gorm.io/gorm"
+)
+
+// MinScheduleInterval is the shortest allowed fixed recrawl interval
+const MinScheduleInterval = time.Minute
+
+// scheduleBatchSize limits how many due schedules are claimed per poll
+const scheduleBatchSize = 100
+
+// NextScheduleRun returns the first time after from at which the schedule
+// should fire. Cron expressions are evaluated in the schedule's timezone;
+// fixed intervals stay aligned to the previous planned run when possible.
+func NextScheduleRun(schedule *models.Schedule, from time.Time) (time.Time, error) {
+	if schedule.CronExpression != "" {
+		loc, err := time.LoadLocation(schedule.Timezone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone: %s", schedule.Timezone)
+		}
+		cron, err := ParseCron(schedule.CronExpression, loc)
+		if err != nil {
+			return time.Time{}, err
+		}
+		next := cron.Next(from.In(loc))
+		if next.IsZero() {
+			return time.Time{}, fmt.Errorf("cron expression never fires")
+		}
+		return next, nil
+	}
+
+	interval := time.Duration(schedule.IntervalSeconds) * time.Second
+	if interval < MinScheduleInterval {
+		return time.Time{}, fmt.Errorf("interval must be at least %d seconds", int(MinScheduleInterval.Seconds()))
+	}
+	if schedule.NextRunAt == nil || schedule.NextRunAt.After(from) {
+		return from.Add(interval), nil
+	}
+	// Skip the runs that were missed, e.g. while the server was down
+	missed := from.Sub(*schedule.NextRunAt) / interval
+	return schedule.NextRunAt.Add((missed + 1) * interval), nil
+}
+
+// Scheduler polls the database for due schedules and queues their recrawls.
+// Schedules are claimed with a conditional update on next_run_at, so when
+// several instances poll the same database each run fires only once.
+type Scheduler struct {
+	db           *gorm.DB
+	queue        *CrawlQueue
+	pollInterval time.Duration
+	jitter       time.Duration
+	wg           sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler. Every queued recrawl is delayed by a
+// random duration of up to jitter to spread out schedules that share a time.
+func NewScheduler(db *gorm.DB, queue *CrawlQueue, pollInterval, jitter time.Duration) *Scheduler {
+	return &Scheduler{
+		db:           db,
+		queue:        queue,
+		pollInterval: pollInterval,
+		jitter:       jitter,
+	}
+}
+
+// Start begins polling until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.runDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the polling loop and pending jittered runs have exited
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) runDue(ctx context.Context) {
+	now := time.Now()
+
+	var due []models.Schedule
+	if err := s.db.WithContext(ctx).
+		Where("enabled = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at").
+		Limit(scheduleBatchSize).
+		Find(&due).Error; err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Failed to load due schedules: %v", err)
+		}
+		return
+	}
+
+	for i := range due {
+		s.fire(ctx, &due[i], now)
+	}
+}
+
+// fire claims a due schedule and queues its recrawl
+func (s *Scheduler) fire(ctx context.Context, schedule *models.Schedule, now time.Time) {
+	next, err := NextScheduleRun(schedule, now)
+	if err != nil {
+		// The schedule can no longer be evaluated; disable it instead of
+		// retrying on every poll
+		s.db.Model(schedule).Updates(map[string]interface{}{
+			"enabled":     false,
+			"last_status": models.ScheduleStatusFailed,
+			"last_error":  err.Error(),
+		})
+		return
+	}
+
+	claim := s.db.WithContext(ctx).Model(&models.Schedule{}).
+		Where("id = ? AND next_run_at = ?", schedule.ID, schedule.NextRunAt).
+		Updates(map[string]interface{}{
+			"next_run_at": next,
+			"last_run_at": now,
+		})
+	if claim.Error != nil || claim.RowsAffected != 1 {
+		// Another instance claimed this run first
+		return
+	}
+
+	delay := time.Duration(0)
+	if s.jitter > 0 {
+		delay = time.Duration(rand.Int63n(int64(s.jitter)))
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		job := CrawlJob{
+			UserID: schedule.UserID,
+			URL:    schedule.URL,
+			Done: func(output *CrawlOutput, err error) {
+				s.recordResult(schedule.ID, output, err)
+			},
+		}
+		if err := s.queue.Enqueue(job); err != nil {
+			s.recordResult(schedule.ID, nil, err)
+		}
+	}()
+}
+
+func (s *Scheduler) recordResult(scheduleID uint, output *CrawlOutput, err error) {
+	updates := map[string]interface{}{
+		"last_status": models.ScheduleStatusCompleted,
+		"last_error":  "",
+	}
+	if err != nil {
+		updates["last_status"] = models.ScheduleStatusFailed
+		updates["last_error"] = err.Error()
+	} else {
+		updates["last_crawl_id"] = output.Result.ID
+	}
+
+	if err := s.db.Model(&models.Schedule{}).Where("id = ?", scheduleID).Updates(updates).Error; err != nil {
+		logger.Error("Failed to record result of schedule %d: %v", scheduleID, err)
+	}
+}
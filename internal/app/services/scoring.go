@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"gorm.io/gorm"
+)
+
+// DefaultScoringRules are the weights and thresholds of the health score
+// unless the user's default scoring profile overrides them. The weights add
+// up to 100 but only their ratios matter.
+//
+//   - broken_links: share of inaccessible links at which the rule scores 0
+//   - heading_structure: number of outline issues at which the rule scores 0
+//   - title: longest title in characters before it counts as too long
+//   - markup: number of markup errors at which the rule scores 0
+var DefaultScoringRules = models.ScoringRules{
+	models.ScoreRuleBrokenLinks:     {Weight: 25, Threshold: 0.1},
+	models.ScoreRuleHeadings:        {Weight: 15, Threshold: 4},
+	models.ScoreRuleTitle:           {Weight: 10, Threshold: 60},
+	models.ScoreRuleMetaDescription: {Weight: 10},
+	models.ScoreRuleLanguage:        {Weight: 5},
+	models.ScoreRuleViewport:        {Weight: 5},
+	models.ScoreRuleSecurityHeaders: {Weight: 15},
+	models.ScoreRuleMarkup:          {Weight: 5, Threshold: 20},
+	models.ScoreRuleCookies:         {Weight: 5},
+	models.ScoreRuleIntegrity:       {Weight: 5},
+}
+
+// rescoreBatchSize is the number of crawls rescored per UPDATE
+const rescoreBatchSize = 100
+
+// ValidateScoringRules checks the rules of a scoring profile. A threshold of
+// 0 keeps the default threshold of the rule.
+func ValidateScoringRules(rules models.ScoringRules) error {
+	for name, rule := range rules {
+		if _, ok := DefaultScoringRules[name]; !ok {
+			return fmt.Errorf("unknown scoring rule: %s", name)
+		}
+		if rule.Weight < 0 || math.IsNaN(rule.Weight) || math.IsInf(rule.Weight, 0) {
+			return fmt.Errorf("weight of %s must not be negative", name)
+		}
+		if rule.Threshold < 0 || math.IsNaN(rule.Threshold) || math.IsInf(rule.Threshold, 0) {
+			return fmt.Errorf("threshold of %s must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// effectiveRules merges the rules of a profile over the defaults. A profile
+// rule without a threshold keeps the default threshold.
+func effectiveRules(profile *models.ScoringProfile) models.ScoringRules {
+	rules := make(models.ScoringRules, len(DefaultScoringRules))
+	for name, rule := range DefaultScoringRules {
+		rules[name] = rule
+	}
+	if profile == nil {
+		return rules
+	}
+	for name, rule := range profile.Rules {
+		if rule.Threshold == 0 {
+			rule.Threshold = DefaultScoringRules[name].Threshold
+		}
+		rules[name] = rule
+	}
+	return rules
+}
+
+// ComputeScore computes the 0-100 health score of a crawl with the rules of
+// profile, or the default rules if profile is nil
+func ComputeScore(result *models.CrawlResult, profile *models.ScoringProfile) models.ScoreBreakdown {
+	breakdown := models.ScoreBreakdown{
+		ProfileName: "default",
+		Items:       make([]models.ScoreItem, 0, len(DefaultScoringRules)),
+	}
+	if profile != nil {
+		id := profile.ID
+		breakdown.ProfileID = &id
+		breakdown.ProfileName = profile.Name
+	}
+
+	rules := effectiveRules(profile)
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	totalWeight := 0.0
+	for _, name := range names {
+		rule := rules[name]
+		value, detail, applicable := evaluateScoreRule(name, rule, result)
+		item := models.ScoreItem{
+			Rule:       name,
+			Weight:     rule.Weight,
+			Threshold:  rule.Threshold,
+			Applicable: applicable && rule.Weight > 0,
+			Value:      round2(value),
+			Detail:     detail,
+		}
+		if item.Applicable {
+			totalWeight += rule.Weight
+		}
+		breakdown.Items = append(breakdown.Items, item)
+	}
+
+	if totalWeight == 0 {
+		return breakdown
+	}
+	score := 0.0
+	for i := range breakdown.Items {
+		item := &breakdown.Items[i]
+		if !item.Applicable {
+			continue
+		}
+		item.MaxPoints = round1(100 * item.Weight / totalWeight)
+		points := 100 * item.Weight * item.Value / totalWeight
+		item.Points = round1(points)
+		score += points
+	}
+	breakdown.Score = round1(score)
+	return breakdown
+}
+
+// evaluateScoreRule returns the value between 0 and 1 of one rule for a
+// crawl, a description of the finding and whether the rule applies
+func evaluateScoreRule(name string, rule models.ScoringRule, result *models.CrawlResult) (float64, string, bool) {
+	fromResponse := result.Metadata.SecurityHeaders.Checked
+
+	switch name {
+	case models.ScoreRuleBrokenLinks:
+		// Inaccessible links are counted as neither internal nor external
+		broken := len(result.InaccessibleLinks)
+		total := result.InternalLinks + result.ExternalLinks + broken
+		if total == 0 {
+			return 1, "no links", true
+		}
+		ratio := float64(broken) / float64(total)
+		return clamp01(1 - ratio/rule.Threshold), fmt.Sprintf("%d of %d links inaccessible", broken, total), true
+
+	case models.ScoreRuleHeadings:
+		issues := len(result.Outline.Issues)
+		if issues == 0 {
+			return 1, "no heading issues", true
+		}
+		types := make([]string, 0, issues)
+		for _, issue := range result.Outline.Issues {
+			types = append(types, issue.Type)
+		}
+		return clamp01(1 - float64(issues)/rule.Threshold),
+			fmt.Sprintf("%d heading issue(s): %s", issues, strings.Join(types, ", ")), true
+
+	case models.ScoreRuleTitle:
+		title := strings.TrimSpace(result.PageTitle)
+		length := utf8.RuneCountInString(title)
+		switch {
+		case length == 0:
+			return 0, "title missing", true
+		case float64(length) > rule.Threshold:
+			return 0.5, fmt.Sprintf("title is %d characters long", length), true
+		}
+		return 1, "title present", true
+
+	case models.ScoreRuleMetaDescription:
+		if result.Metadata.Description == "" {
+			return 0, "meta description missing", true
+		}
+		return 1, "meta description present", true
+
+	case models.ScoreRuleLanguage:
+		switch {
+		case result.Content.DeclaredLanguage == "":
+			return 0, "no lang attribute", true
+		case result.Content.LanguageMismatch:
+			return 0.5, "declared language does not match the content", true
+		}
+		return 1, "language declared", true
+
+	case models.ScoreRuleViewport:
+		if result.Metadata.Viewport == "" {
+			return 0, "viewport meta tag missing", true
+		}
+		return 1, "viewport meta tag present", true
+
+	case models.ScoreRuleSecurityHeaders:
+		if !fromResponse {
+			return 0, "no response headers", false
+		}
+		headers := result.Metadata.SecurityHeaders
+		total := len(headers.Present) + len(headers.Missing)
+		if total == 0 {
+			return 1, "", true
+		}
+		detail := "all security headers present"
+		if len(headers.Missing) > 0 {
+			detail = "missing " + strings.Join(headers.Missing, ", ")
+		}
+		return float64(len(headers.Present)) / float64(total), detail, true
+
+	case models.ScoreRuleMarkup:
+		markupErrors := result.Markup.Summary.Errors
+		return clamp01(1 - float64(markupErrors)/rule.Threshold), fmt.Sprintf("%d markup error(s)", markupErrors), true
+
+	case models.ScoreRuleCookies:
+		if !fromResponse {
+			return 0, "no response cookies", false
+		}
+		summary := result.Cookies.Summary
+		if summary.Total == 0 {
+			return 1, "no cookies set", true
+		}
+		return 1 - float64(summary.Insecure)/float64(summary.Total),
+			fmt.Sprintf("%d of %d cookies insecure", summary.Insecure, summary.Total), true
+
+	case models.ScoreRuleIntegrity:
+		external := 0
+		for _, resource := range result.ThirdParties.Resources {
+			if resource.Type == models.ResourceTypeScript || resource.Type == models.ResourceTypeStylesheet {
+				external++
+			}
+		}
+		if external == 0 {
+			return 1, "no external scripts or stylesheets", true
+		}
+		missing := len(result.ThirdParties.SRIIssues)
+		return 1 - float64(missing)/float64(external),
+			fmt.Sprintf("%d of %d external scripts and stylesheets without valid integrity", missing, external), true
+	}
+	return 0, "", false
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// DefaultScoringProfile returns the default scoring profile of userID, or
+// nil if the user has none
+func DefaultScoringProfile(db *gorm.DB, userID uint64) (*models.ScoringProfile, error) {
+	var profile models.ScoringProfile
+	err := db.Where("user_id = ? AND is_default = ?", userID, true).First(&profile).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scoring profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// RescoreCrawls recomputes the stored health scores of the completed crawls
+// of userID with their current default scoring profile, one UPDATE per
+// batch of crawls. Crawls stored before scoring existed have no score and
+// are left alone, as they lack some of the findings it uses.
+func RescoreCrawls(db *gorm.DB, userID uint64) (int, error) {
+	profile, err := DefaultScoringProfile(db, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	rescored := 0
+	var batch []models.CrawlResult
+	result := db.Where("user_id = ? AND status = ? AND health_score IS NOT NULL", userID, models.CrawlStatusCompleted).
+		FindInBatches(&batch, rescoreBatchSize, func(tx *gorm.DB, _ int) error {
+			ids := make([]uint, len(batch))
+			scores := make([]interface{}, 0, 2*len(batch))
+			breakdowns := make([]interface{}, 0, 2*len(batch))
+			for i := range batch {
+				breakdown := ComputeScore(&batch[i], profile)
+				encoded, err := json.Marshal(breakdown)
+				if err != nil {
+					return err
+				}
+				ids[i] = batch[i].ID
+				scores = append(scores, batch[i].ID, breakdown.Score)
+				breakdowns = append(breakdowns, batch[i].ID, string(encoded))
+			}
+
+			cases := "CASE id" + strings.Repeat(" WHEN ? THEN ?", len(batch)) + " END"
+			if err := db.Model(&models.CrawlResult{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+				"health_score":    gorm.Expr(cases, scores...),
+				"score_breakdown": gorm.Expr(cases, breakdowns...),
+			}).Error; err != nil {
+				return err
+			}
+			rescored += len(batch)
+			return nil
+		})
+	if result.Error != nil {
+		return rescored, fmt.Errorf("failed to rescore crawls: %w", result.Error)
+	}
+	return rescored, nil
+}
+
+// Rescorer rescores the crawls of users whose default scoring profile
+// changed in the background. Each user has at most one rescore running;
+// changes made while it runs trigger one more once it is done.
+type Rescorer struct {
+	db *gorm.DB
+
+	mu      sync.Mutex
+	ctx     context.Context
+	running map[uint64]bool
+	pending map[uint64]bool
+	wg      sync.WaitGroup
+}
+
+// NewRescorer creates a rescorer
+func NewRescorer(db *gorm.DB) *Rescorer {
+	return &Rescorer{
+		db:      db,
+		ctx:     context.Background(),
+		running: make(map[uint64]bool),
+		pending: make(map[uint64]bool),
+	}
+}
+
+// Start sets the context rescores run in. They stop when ctx is cancelled.
+func (r *Rescorer) Start(ctx context.Context) {
+	r.mu.Lock()
+	r.ctx = ctx
+	r.mu.Unlock()
+}
+
+// Wait blocks until the running rescores have stopped
+func (r *Rescorer) Wait() {
+	r.wg.Wait()
+}
+
+// Rescore schedules a rescore of the crawls of userID
+func (r *Rescorer) Rescore(userID uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running[userID] {
+		r.pending[userID] = true
+		return
+	}
+	r.running[userID] = true
+
+	ctx := r.ctx
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			if ctx.Err() == nil {
+				if rescored, err := RescoreCrawls(r.db.WithContext(ctx), userID); err != nil {
+					logger.Error("Failed to rescore the crawls of user %d: %v", userID, err)
+				} else {
+					logger.Info("Rescored %d crawls of user %d", rescored, userID)
+				}
+			}
+
+			r.mu.Lock()
+			if !r.pending[userID] {
+				delete(r.running, userID)
+				r.mu.Unlock()
+				return
+			}
+			delete(r.pending, userID)
+			r.mu.Unlock()
+		}
+	}()
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// healthyCrawl returns a crawl that passes every scoring rule
+func healthyCrawl() *models.CrawlResult {
+	result := &models.CrawlResult{
+		Status:        models.CrawlStatusCompleted,
+		PageTitle:     "Home",
+		InternalLinks: 8,
+		ExternalLinks: 2,
+	}
+	result.Metadata.Description = "A page"
+	result.Metadata.Viewport = "width=device-width"
+	result.Metadata.SecurityHeaders = models.SecurityHeaders{Checked: true, Present: []string{"Content-Security-Policy"}}
+	result.Content.DeclaredLanguage = "en"
+	return result
+}
+
+func TestValidateScoringRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   models.ScoringRules
+		wantErr bool
+	}{
+		{name: "empty", rules: models.ScoringRules{}},
+		{name: "weights only", rules: models.ScoringRules{models.ScoreRuleBrokenLinks: {Weight: 50}, models.ScoreRuleTitle: {Weight: 0}}},
+		{name: "zero threshold keeps the default", rules: models.ScoringRules{models.ScoreRuleMarkup: {Weight: 10, Threshold: 0}}},
+		{name: "custom threshold", rules: models.ScoringRules{models.ScoreRuleHeadings: {Weight: 10, Threshold: 2}}},
+		{name: "unknown rule", rules: models.ScoringRules{"speed": {Weight: 10}}, wantErr: true},
+		{name: "negative weight", rules: models.ScoringRules{models.ScoreRuleTitle: {Weight: -1}}, wantErr: true},
+		{name: "infinite weight", rules: models.ScoringRules{models.ScoreRuleTitle: {Weight: math.Inf(1)}}, wantErr: true},
+		{name: "negative threshold", rules: models.ScoringRules{models.ScoreRuleMarkup: {Weight: 5, Threshold: -3}}, wantErr: true},
+		{name: "NaN threshold", rules: models.ScoringRules{models.ScoreRuleMarkup: {Weight: 5, Threshold: math.NaN()}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateScoringRules(tt.rules)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateScoringRules() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateScoreRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       string
+		threshold  float64
+		modify     func(*models.CrawlResult)
+		want       float64
+		applicable bool
+	}{
+		{name: "no links", rule: models.ScoreRuleBrokenLinks, threshold: 0.1, modify: func(r *models.CrawlResult) {
+			r.InternalLinks, r.ExternalLinks = 0, 0
+		}, want: 1, applicable: true},
+		{name: "all links broken", rule: models.ScoreRuleBrokenLinks, threshold: 0.1, modify: func(r *models.CrawlResult) {
+			r.InternalLinks, r.ExternalLinks = 0, 0
+			r.InaccessibleLinks = models.StringSlice{"/a", "/b"}
+		}, want: 0, applicable: true},
+		{name: "broken share counts broken links", rule: models.ScoreRuleBrokenLinks, threshold: 0.5, modify: func(r *models.CrawlResult) {
+			r.InternalLinks, r.ExternalLinks = 8, 0
+			r.InaccessibleLinks = models.StringSlice{"/a", "/b"}
+		}, want: 0.6, applicable: true},
+		{name: "heading issues", rule: models.ScoreRuleHeadings, threshold: 4, modify: func(r *models.CrawlResult) {
+			r.Outline.Issues = []models.OutlineIssue{{Type: "skipped_level"}}
+		}, want: 0.75, applicable: true},
+		{name: "title missing", rule: models.ScoreRuleTitle, threshold: 60, modify: func(r *models.CrawlResult) {
+			r.PageTitle = "  "
+		}, want: 0, applicable: true},
+		{name: "title too long", rule: models.ScoreRuleTitle, threshold: 3, want: 0.5, applicable: true},
+		{name: "language mismatch", rule: models.ScoreRuleLanguage, modify: func(r *models.CrawlResult) {
+			r.Content.LanguageMismatch = true
+		}, want: 0.5, applicable: true},
+		{name: "security headers", rule: models.ScoreRuleSecurityHeaders, modify: func(r *models.CrawlResult) {
+			r.Metadata.SecurityHeaders.Missing = []string{"Strict-Transport-Security", "X-Frame-Options", "Referrer-Policy"}
+		}, want: 0.25, applicable: true},
+		{name: "security headers of an upload", rule: models.ScoreRuleSecurityHeaders, modify: func(r *models.CrawlResult) {
+			r.Metadata.SecurityHeaders = models.SecurityHeaders{}
+		}, want: 0, applicable: false},
+		{name: "cookies of an upload", rule: models.ScoreRuleCookies, modify: func(r *models.CrawlResult) {
+			r.Metadata.SecurityHeaders = models.SecurityHeaders{}
+		}, want: 0, applicable: false},
+		{name: "insecure cookies", rule: models.ScoreRuleCookies, modify: func(r *models.CrawlResult) {
+			r.Cookies.Summary = models.CookieSummary{Total: 4, Insecure: 1}
+		}, want: 0.75, applicable: true},
+		{name: "markup errors past the threshold", rule: models.ScoreRuleMarkup, threshold: 20, modify: func(r *models.CrawlResult) {
+			r.Markup.Summary.Errors = 25
+		}, want: 0, applicable: true},
+		{name: "unknown rule", rule: "speed", want: 0, applicable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := healthyCrawl()
+			if tt.modify != nil {
+				tt.modify(result)
+			}
+			got, _, applicable := evaluateScoreRule(tt.rule, models.ScoringRule{Weight: 1, Threshold: tt.threshold}, result)
+			if math.Abs(got-tt.want) > 1e-9 || applicable != tt.applicable {
+				t.Errorf("evaluateScoreRule(%s) = %v, %v, want %v, %v", tt.rule, got, applicable, tt.want, tt.applicable)
+			}
+		})
+	}
+}
+
+func TestComputeScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *models.ScoringProfile
+		modify  func(*models.CrawlResult)
+		want    float64
+	}{
+		{name: "healthy page", want: 100},
+		{name: "missing meta description", modify: func(r *models.CrawlResult) {
+			r.Metadata.Description = ""
+		}, want: 90},
+		{name: "upload skips response rules", modify: func(r *models.CrawlResult) {
+			r.Metadata.SecurityHeaders = models.SecurityHeaders{}
+			r.Metadata.Description = ""
+		}, want: 87.5},
+		{name: "profile weights", profile: &models.ScoringProfile{Name: "meta only", Rules: models.ScoringRules{
+			models.ScoreRuleBrokenLinks:     {Weight: 0},
+			models.ScoreRuleHeadings:        {Weight: 0},
+			models.ScoreRuleTitle:           {Weight: 0},
+			models.ScoreRuleLanguage:        {Weight: 0},
+			models.ScoreRuleViewport:        {Weight: 0},
+			models.ScoreRuleSecurityHeaders: {Weight: 0},
+			models.ScoreRuleMarkup:          {Weight: 0},
+			models.ScoreRuleCookies:         {Weight: 0},
+			models.ScoreRuleIntegrity:       {Weight: 0},
+		}}, modify: func(r *models.CrawlResult) {
+			r.Metadata.Description = ""
+		}, want: 0},
+		{name: "profile without threshold keeps the default", profile: &models.ScoringProfile{Name: "links", Rules: models.ScoringRules{
+			models.ScoreRuleBrokenLinks: {Weight: 75},
+		}}, modify: func(r *models.CrawlResult) {
+			// 1 of 20 links broken is half of the default 0.1 threshold
+			r.InternalLinks, r.ExternalLinks = 19, 0
+			r.InaccessibleLinks = models.StringSlice{"/gone"}
+		}, want: 75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := healthyCrawl()
+			if tt.modify != nil {
+				tt.modify(result)
+			}
+			breakdown := ComputeScore(result, tt.profile)
+			if breakdown.Score != tt.want {
+				t.Errorf("score = %v, want %v", breakdown.Score, tt.want)
+			}
+			if len(breakdown.Items) != len(DefaultScoringRules) {
+				t.Errorf("got %d items, want %d", len(breakdown.Items), len(DefaultScoringRules))
+			}
+		})
+	}
+}
+
+func TestRescoreCrawls(t *testing.T) {
+	db := newTestDB(t, &models.CrawlResult{}, &models.ScoringProfile{})
+
+	score := 0.0
+	crawls := []*models.CrawlResult{healthyCrawl(), healthyCrawl(), healthyCrawl(), healthyCrawl()}
+	crawls[1].Metadata.Description = ""
+	// Crawls stored before scoring existed and failed crawls keep no score
+	crawls[2].HealthScore = nil
+	crawls[3].Status = models.CrawlStatusFailed
+	for i, crawl := range crawls {
+		crawl.UserID = 1
+		crawl.URL = "https://example.com/"
+		if i != 2 {
+			crawl.HealthScore = &score
+		}
+		if err := db.Create(crawl).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	profile := models.ScoringProfile{UserID: 1, Name: "meta", IsDefault: true, Rules: models.ScoringRules{
+		models.ScoreRuleMetaDescription: {Weight: 90},
+	}}
+	if err := db.Create(&profile).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rescored, err := RescoreCrawls(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rescored != 2 {
+		t.Errorf("rescored %d crawls, want 2", rescored)
+	}
+
+	want := []*float64{floatPtr(100), floatPtr(ComputeScore(crawls[1], &profile).Score), nil, &score}
+	for i, crawl := range crawls {
+		var stored models.CrawlResult
+		if err := db.First(&stored, crawl.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case want[i] == nil && stored.HealthScore != nil:
+			t.Errorf("crawl %d score = %v, want none", i, *stored.HealthScore)
+		case want[i] != nil && (stored.HealthScore == nil || *stored.HealthScore != *want[i]):
+			t.Errorf("crawl %d score = %v, want %v", i, stored.HealthScore, *want[i])
+		}
+		if i < 2 && stored.ScoreBreakdown.ProfileName != "meta" {
+			t.Errorf("crawl %d breakdown profile = %q, want meta", i, stored.ScoreBreakdown.ProfileName)
+		}
+	}
+}
+
+func TestRescorer(t *testing.T) {
+	db := newTestDB(t, &models.CrawlResult{}, &models.ScoringProfile{})
+
+	score := 0.0
+	crawl := healthyCrawl()
+	crawl.UserID = 1
+	crawl.URL = "https://example.com/"
+	crawl.HealthScore = &score
+	if err := db.Create(crawl).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rescorer := NewRescorer(db)
+	rescorer.Start(context.Background())
+	// Requests made while a rescore runs are coalesced into one more run
+	for i := 0; i < 5; i++ {
+		rescorer.Rescore(1)
+	}
+	rescorer.Wait()
+
+	var stored models.CrawlResult
+	if err := db.First(&stored, crawl.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.HealthScore == nil || *stored.HealthScore != 100 {
+		t.Errorf("score = %v, want 100", stored.HealthScore)
+	}
+	if len(rescorer.running) != 0 || len(rescorer.pending) != 0 {
+		t.Errorf("rescorer still tracks %d running and %d pending users", len(rescorer.running), len(rescorer.pending))
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
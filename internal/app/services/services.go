@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/config"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"github.com/ayeshakhan-29/test-task-BE/internal/storage"
+	"gorm.io/gorm"
+)
+
+// Services bundles the long-lived services shared by the HTTP handlers and
+// the background workers
+type Services struct {
+	Snapshots *SnapshotService
+	Crawler   *Crawler
+	Queue     *CrawlQueue
+	Scheduler *Scheduler
+	Webhooks  *WebhookDispatcher
+	Progress  *ProgressHub
+	Batches   *BatchRunner
+	Hosts     *HostScheduler
+	URLs      *URLCanonicalizer
+	Rescorer  *Rescorer
+
+	cfg *config.Config
+}
+
+// New wires up the application services
+func New(db *gorm.DB, cfg *config.Config) *Services {
+	snapshots := NewSnapshotService(db, storage.NewLocalStore(cfg.Storage.Path), cfg.Storage.SnapshotQuota)
+	webhooks := NewWebhookDispatcher(db, nil, cfg.Webhooks.MaxAttempts, cfg.Webhooks.BaseBackoff, cfg.Webhooks.PollInterval)
+	progress := NewProgressHub()
+	var linkCacheDB *gorm.DB
+	if cfg.LinkCache.Persistent {
+		linkCacheDB = db
+	}
+	urls := NewURLCanonicalizer(cfg.URLs.StripTrailingSlash, cfg.URLs.TrackingParams)
+	links := NewLinkStatusCache(linkCacheDB, urls, cfg.LinkCache.SuccessTTL, cfg.LinkCache.FailureTTL)
+	hosts := NewHostScheduler(cfg.Politeness.Delay, cfg.Politeness.MaxCrawlDelay, cfg.Politeness.HostConcurrency, cfg.Politeness.RespectRobots)
+	techs, err := LoadFingerprinter(cfg.Analysis.TechRulesPath)
+	if err != nil {
+		logger.Error("Failed to load technology rules from %s, using the bundled rules: %v", cfg.Analysis.TechRulesPath, err)
+		techs, _ = LoadFingerprinter("")
+	}
+	crawler := NewCrawler(db, snapshots, webhooks, progress, links, hosts, urls, techs, cfg.History.RunLimit)
+	queue := NewCrawlQueue(crawler, cfg.Scheduler.Workers, cfg.Scheduler.QueueSize)
+
+	return &Services{
+		Snapshots: snapshots,
+		Crawler:   crawler,
+		Queue:     queue,
+		Scheduler: NewScheduler(db, queue, cfg.Scheduler.PollInterval, cfg.Scheduler.Jitter),
+		Webhooks:  webhooks,
+		Progress:  progress,
+		Batches:   NewBatchRunner(db, crawler, cfg.Batch.Concurrency, cfg.Batch.MaxURLs),
+		Hosts:     hosts,
+		URLs:      urls,
+		Rescorer:  NewRescorer(db),
+		cfg:       cfg,
+	}
+}
+
+// Start launches the background workers. They stop when ctx is cancelled.
+func (s *Services) Start(ctx context.Context) {
+	s.Queue.Start(ctx)
+	s.Webhooks.Start(ctx)
+	s.Batches.Start(ctx)
+	s.Snapshots.Start(ctx, s.cfg.Storage.GCInterval)
+	s.Rescorer.Start(ctx)
+	if s.cfg.Scheduler.Enabled {
+		s.Scheduler.Start(ctx)
+	}
+}
+
+// Wait blocks until the background workers have stopped or the timeout
+// elapses, whichever comes first
+func (s *Services) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		s.Scheduler.Wait()
+		s.Queue.Wait()
+		s.Webhooks.Wait()
+		s.Batches.Wait()
+		s.Snapshots.Wait()
+		s.Rescorer.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"os"
+	"testing"
+
+	applogger "github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestMain silences the application logger, which is only initialized by
+// the server
+func TestMain(m *testing.M) {
+	applogger.SetLevel(applogger.FatalLevel + 1)
+	os.Exit(m.Run())
+}
+
+// newTestDB opens a private in-memory SQLite database with the tables of the
+// given models
+func newTestDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
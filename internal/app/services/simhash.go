@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// shingleSize is the number of consecutive words hashed together. Shingles
+// make the fingerprint sensitive to word order, not just vocabulary.
+const shingleSize = 3
+
+// ContentFingerprint returns the hex-encoded 64-bit SimHash of the visible
+// page text, or an empty string when the page has no text
+func ContentFingerprint(doc *goquery.Document) string {
+	hash, ok := SimHash(ExtractVisibleText(doc))
+	if !ok {
+		return ""
+	}
+	return FormatFingerprint(hash)
+}
+
+// SimHash computes a 64-bit SimHash over word shingles of text. Similar texts
+// produce fingerprints with a small Hamming distance. ok is false when the
+// text contains no words.
+func SimHash(text string) (hash uint64, ok bool) {
+	words := tokenizeWords(text)
+	if len(words) == 0 {
+		return 0, false
+	}
+
+	size := shingleSize
+	if len(words) < size {
+		size = len(words)
+	}
+
+	var weights [64]int
+	for i := 0; i+size <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+size], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+	return hash, true
+}
+
+// FormatFingerprint encodes a fingerprint as 16 hex characters
+func FormatFingerprint(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// ParseFingerprint decodes a fingerprint produced by FormatFingerprint
+func ParseFingerprint(s string) (uint64, error) {
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// Similarity returns the share of identical bits in two fingerprints, from 0
+// (completely different) to 1 (identical)
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
+
+// ClusterFingerprints groups fingerprints into clusters of near-duplicates.
+// Two fingerprints end up in the same cluster when they are connected by a
+// chain of pairs whose similarity is at least threshold. Only clusters with
+// more than one member are returned; members are indexes into hashes.
+func ClusterFingerprints(hashes []uint64, threshold float64) [][]int {
+	parent := make([]int, len(hashes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range hashes {
+		for j := i + 1; j < len(hashes); j++ {
+			if Similarity(hashes[i], hashes[j]) >= threshold {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	var order []int
+	for i := range hashes {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	clusters := make([][]int, 0)
+	for _, root := range order {
+		if len(groups[root]) > 1 {
+			clusters = append(clusters, groups[root])
+		}
+	}
+	return clusters
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"github.com/ayeshakhan-29/test-task-BE/internal/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrQuotaExceeded is returned when storing a snapshot would push the user
+// over their storage quota
+var ErrQuotaExceeded = errors.New("snapshot storage quota exceeded")
+
+// snapshotGCGrace is how long a snapshot stays after it was last stored
+// before garbage collection may delete it. It covers the time between a
+// crawl storing its snapshot and saving the run that references it.
+const snapshotGCGrace = time.Hour
+
+// SnapshotService stores gzip-compressed, content-addressed HTML snapshots in
+// a BlobStore and keeps the per-user quota accounting in the database
+type SnapshotService struct {
+	db    *gorm.DB
+	store storage.BlobStore
+	quota int64
+
+	// mu keeps garbage collection from deleting a blob while Save reuses it
+	mu sync.RWMutex
+	wg sync.WaitGroup
+}
+
+// NewSnapshotService creates a snapshot service. A quota of 0 or less
+// disables the per-user limit.
+func NewSnapshotService(db *gorm.DB, store storage.BlobStore, quota int64) *SnapshotService {
+	return &SnapshotService{db: db, store: store, quota: quota}
+}
+
+// Quota returns the per-user storage quota in bytes
+func (s *SnapshotService) Quota() int64 {
+	return s.quota
+}
+
+// Save stores the HTML for userID and returns its snapshot hash. Pages that
+// were already stored are deduplicated and only count once towards a user's
+// quota.
+func (s *SnapshotService) Save(ctx context.Context, userID uint64, html []byte) (string, error) {
+	sum := sha256.Sum256(html)
+	hash := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var existing models.Snapshot
+	err := s.db.WithContext(ctx).First(&existing, "hash = ?", hash).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return "", fmt.Errorf("failed to look up snapshot: %w", err)
+	}
+	found := err == nil
+
+	if found {
+		stored, err := s.store.Exists(ctx, hash)
+		if err != nil {
+			return "", err
+		}
+		if stored {
+			if err := s.checkQuota(ctx, userID, hash, existing.CompressedSize); err != nil {
+				return "", err
+			}
+			if err := s.db.WithContext(ctx).Model(&existing).Update("referenced_at", now).Error; err != nil {
+				return "", fmt.Errorf("failed to record snapshot: %w", err)
+			}
+			return hash, nil
+		}
+	}
+
+	compressed, err := compress(html)
+	if err != nil {
+		return "", err
+	}
+	if err := s.checkQuota(ctx, userID, hash, int64(len(compressed))); err != nil {
+		return "", err
+	}
+
+	if err := s.store.Put(ctx, hash, compressed); err != nil {
+		return "", err
+	}
+
+	snapshot := models.Snapshot{
+		Hash:           hash,
+		Size:           int64(len(html)),
+		CompressedSize: int64(len(compressed)),
+		ReferencedAt:   now,
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"size", "compressed_size", "referenced_at"}),
+	}).Create(&snapshot).Error; err != nil {
+		return "", fmt.Errorf("failed to record snapshot: %w", err)
+	}
+
+	return hash, nil
+}
+
+// Open returns a reader over the decompressed HTML of a snapshot
+func (s *SnapshotService) Open(ctx context.Context, hash string) (io.ReadCloser, error) {
+	blob, err := s.store.Get(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(blob)
+	if err != nil {
+		blob.Close()
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	return &gzipReadCloser{Reader: gz, blob: blob}, nil
+}
+
+// Start launches the garbage collection loop, which deletes the snapshots
+// no crawl references every interval. It stops when ctx is cancelled.
+func (s *SnapshotService) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if deleted, err := s.Collect(ctx, time.Now().Add(-snapshotGCGrace)); err != nil {
+				logger.Error("Snapshot garbage collection failed: %v", err)
+			} else if deleted > 0 {
+				logger.Info("Deleted %d unreferenced snapshots", deleted)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the garbage collection loop has exited
+func (s *SnapshotService) Wait() {
+	s.wg.Wait()
+}
+
+// Collect deletes the blobs and records of the snapshots that no crawl
+// references and that were last stored before cutoff. It returns the number
+// of snapshots deleted.
+func (s *SnapshotService) Collect(ctx context.Context, cutoff time.Time) (int, error) {
+	var hashes []string
+	if err := s.unreferenced(ctx, cutoff).Pluck("hash", &hashes).Error; err != nil {
+		return 0, fmt.Errorf("failed to find unreferenced snapshots: %w", err)
+	}
+
+	deleted := 0
+	for _, hash := range hashes {
+		if ctx.Err() != nil {
+			return deleted, ctx.Err()
+		}
+		ok, err := s.delete(ctx, hash, cutoff)
+		if err != nil {
+			return deleted, err
+		}
+		if ok {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// delete removes one snapshot if it is still unreferenced. The blob goes
+// first so that a failure never leaves a blob without a record; a record
+// without a blob is stored again by the next Save.
+func (s *SnapshotService) delete(ctx context.Context, hash string, cutoff time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	if err := s.unreferenced(ctx, cutoff).Where("hash = ?", hash).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check snapshot references: %w", err)
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	if err := s.store.Delete(ctx, hash); err != nil {
+		return false, err
+	}
+	if err := s.db.WithContext(ctx).Delete(&models.Snapshot{}, "hash = ?", hash).Error; err != nil {
+		return false, fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return true, nil
+}
+
+// unreferenced selects the snapshots last stored before cutoff that no crawl
+// references
+func (s *SnapshotService) unreferenced(ctx context.Context, cutoff time.Time) *gorm.DB {
+	references := s.db.Model(&models.CrawlResult{}).
+		Select("1").
+		Where("crawl_results.snapshot_hash = snapshots.hash")
+	return s.db.WithContext(ctx).Model(&models.Snapshot{}).
+		Where("referenced_at < ?", cutoff).
+		Where("NOT EXISTS (?)", references)
+}
+
+// Usage returns the number of bytes and distinct snapshots referenced by the
+// user's crawls
+func (s *SnapshotService) Usage(ctx context.Context, userID uint64) (int64, int64, error) {
+	var usage struct {
+		Bytes int64
+		Count int64
+	}
+	err := s.db.WithContext(ctx).Model(&models.Snapshot{}).
+		Select("COALESCE(SUM(compressed_size), 0) AS bytes, COUNT(*) AS count").
+		Where("hash IN (?)", s.userHashes(ctx, userID)).
+		Scan(&usage).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to compute storage usage: %w", err)
+	}
+	return usage.Bytes, usage.Count, nil
+}
+
+// checkQuota verifies that referencing the snapshot does not exceed the quota
+func (s *SnapshotService) checkQuota(ctx context.Context, userID uint64, hash string, size int64) error {
+	if s.quota <= 0 {
+		return nil
+	}
+
+	var referenced int64
+	if err := s.db.WithContext(ctx).Model(&models.CrawlResult{}).
+		Where("user_id = ? AND snapshot_hash = ?", userID, hash).
+		Count(&referenced).Error; err != nil {
+		return fmt.Errorf("failed to check snapshot references: %w", err)
+	}
+	if referenced > 0 {
+		// Already counted towards the user's usage
+		return nil
+	}
+
+	used, _, err := s.Usage(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if used+size > s.quota {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+func (s *SnapshotService) userHashes(ctx context.Context, userID uint64) *gorm.DB {
+	return s.db.WithContext(ctx).Model(&models.CrawlResult{}).
+		Distinct("snapshot_hash").
+		Where("user_id = ? AND snapshot_hash <> ''", userID)
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gz.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	blob io.Closer
+}
+
+func (r *gzipReadCloser) Close() error {
+	r.Reader.Close()
+	return r.blob.Close()
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/storage"
+)
+
+func TestSnapshotCollect(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &models.Snapshot{}, &models.CrawlResult{})
+	store := storage.NewLocalStore(t.TempDir())
+	snapshots := NewSnapshotService(db, store, 0)
+
+	kept, err := snapshots.Save(ctx, 1, []byte("<html>kept</html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphaned, err := snapshots.Save(ctx, 1, []byte("<html>orphaned</html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recent, err := snapshots.Save(ctx, 1, []byte("<html>recent</html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.CrawlResult{URL: "https://example.com/", UserID: 1, SnapshotHash: kept}).Error; err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * snapshotGCGrace)
+	if err := db.Model(&models.Snapshot{}).Where("hash IN ?", []string{kept, orphaned}).Update("referenced_at", old).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := snapshots.Collect(ctx, time.Now().Add(-snapshotGCGrace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("Collect deleted %d snapshots, want 1", deleted)
+	}
+
+	for hash, want := range map[string]bool{kept: true, orphaned: false, recent: true} {
+		stored, err := store.Exists(ctx, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows int64
+		db.Model(&models.Snapshot{}).Where("hash = ?", hash).Count(&rows)
+		if stored != want || (rows == 1) != want {
+			t.Errorf("snapshot %s: blob stored %v, record present %v, want %v", hash[:8], stored, rows == 1, want)
+		}
+	}
+
+	// Storing a collected page again brings its blob back
+	again, err := snapshots.Save(ctx, 1, []byte("<html>orphaned</html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := snapshots.Open(ctx, again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if html, _ := io.ReadAll(r); string(html) != "<html>orphaned</html>" {
+		t.Errorf("Open returned %q after collection", html)
+	}
+}
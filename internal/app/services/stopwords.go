@@ -0,0 +1,52 @@
+package services
+
+import "strings"
+
+// stopwords maps ISO 639-1 language codes to their most frequent function
+// words. The lists double as the signal for language detection, so they
+// deliberately favour words that are distinctive for each language.
+var stopwords = map[string]map[string]struct{}{
+	"en": wordSet(`a about above after again against all am an and any are as at be because been
+		before being below between both but by can could did do does doing down during each few for
+		from further had has have having he her here hers herself him himself his how i if in into is
+		it its itself just me more most my myself no nor not now of off on once only or other our ours
+		ourselves out over own same she should so some such than that the their theirs them themselves
+		then there these they this those through to too under until up very was we were what when where
+		which while who whom why will with would you your yours yourself yourselves`),
+	"es": wordSet(`a al algo algunas algunos ante antes como con contra cual cuando de del desde donde
+		durante e el ella ellas ellos en entre era erais eran eras eres es esa esas ese eso esos esta
+		estaba estado estamos estan estar este esto estos fue fueron ha hay la las le les lo los mas me
+		mi mis mucho muy nada ni no nos nosotros o os otra otro para pero poco por porque que quien se
+		sea ser si sin sobre su sus tambien te tiene todo tu tus un una uno unos y ya yo`),
+	"fr": wordSet(`ai au aux avec ce ces cette dans de des du elle elles en est et eu il ils je la le
+		les leur leurs lui ma mais me meme mes moi mon ne nos notre nous on ou par pas pour qu que qui sa
+		se ses son sont sur ta te tes toi ton tu un une vos votre vous y ete etre avait avons ont suis
+		sommes etes fait comme plus tout tous aussi bien tres sans sous`),
+	"de": wordSet(`aber alle allem allen aller als also am an auch auf aus bei bin bis bist da damit
+		dann das dass dein deine dem den der des dich die dir doch dort du durch ein eine einem einen
+		einer eines er es euch euer fur hab habe haben hat hatte ich ihm ihn ihr ihre im in ist ja jede
+		jeder kann kein keine mich mit muss nach nicht nichts noch nun nur ob oder ohne sehr sein seine
+		sich sie sind so uber um und uns unser unter vom von vor war waren warum was weil welche wenn
+		wer wie wir wird wo zu zum zur`),
+	"it": wordSet(`a ad al alla alle anche avere ha hanno ho che chi ci come con cosa da dal dalla dei
+		del della delle di dove e ed era essere gli ha il in io la le lei lo loro lui ma mi mia mio ne
+		nei nel nella noi non o per perche piu quale quando quella quello questa questo se sei si sono
+		su sua suo sul sulla tra tu tutti tutto un una uno vi voi`),
+	"pt": wordSet(`a ao aos as com como da das de dela dele depois do dos e ela elas ele eles em entre
+		era essa esse esta este eu foi foram ha isso isto ja lhe mais mas me mesmo meu minha muito na
+		nao nas nem no nos nossa nosso num numa o os ou para pela pelo por qual quando que quem se sem
+		ser seu sua suas so tambem te tem tu um uma voce`),
+	"nl": wordSet(`aan al alles als altijd andere ben bij daar dan dat de der deze die dit doch doen
+		door dus een eens en er ge geen geweest haar had heb hebben heeft hem het hier hij hoe hun iets
+		ik in is ja je kan kon kunnen maar me meer men met mij mijn moet na naar niet niets nog nu of
+		om omdat onder ons ook op over reeds te tegen toch toen tot u uit uw van veel voor want waren
+		was wat werd wezen wie wil worden wordt zal ze zelf zich zij zijn zo zonder zou`),
+}
+
+func wordSet(words string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, w := range strings.Fields(words) {
+		set[w] = struct{}{}
+	}
+	return set
+}
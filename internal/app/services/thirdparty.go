@@ -0,0 +1,243 @@
+package services
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"golang.org/x/net/publicsuffix"
+)
+
+// bundledTrackerList maps tracker, advertising and other well-known
+// third-party domains to their company and category
+//
+//go:embed rules/trackers.json
+var bundledTrackerList []byte
+
+// trackerEntry is a domain of the tracker list
+type trackerEntry struct {
+	Company  string `json:"company"`
+	Category string `json:"category"`
+}
+
+// trackerDomains is the parsed tracker list
+var trackerDomains = mustParseTrackerList(bundledTrackerList)
+
+// trackingCategories are the tracker list categories that count as trackers
+var trackingCategories = map[string]bool{
+	"Advertising":    true,
+	"Analytics":      true,
+	"Marketing":      true,
+	"Session replay": true,
+	"Social":         true,
+	"Tag management": true,
+}
+
+// sriDigestSizes are the digest sizes in bytes of the hash algorithms
+// Subresource Integrity supports
+var sriDigestSizes = map[string]int{"sha256": 32, "sha384": 48, "sha512": 64}
+
+func mustParseTrackerList(data []byte) map[string]trackerEntry {
+	var list map[string]trackerEntry
+	if err := json.Unmarshal(data, &list); err != nil {
+		panic("invalid bundled tracker list: " + err.Error())
+	}
+	return list
+}
+
+// lookupTracker finds the tracker list entry of host or of its closest
+// listed parent domain
+func lookupTracker(host string) (trackerEntry, bool) {
+	for {
+		if entry, ok := trackerDomains[host]; ok {
+			return entry, true
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			return trackerEntry{}, false
+		}
+		host = host[i+1:]
+	}
+}
+
+// siteOf returns the registrable domain of host, e.g. example.co.uk for
+// www.example.co.uk, or host itself when it has none, as for IP addresses
+func siteOf(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	if site, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return site
+	}
+	return host
+}
+
+// AnalyzeThirdParties inventories the scripts, stylesheets, iframes and
+// tracking pixels a page loads from other sites, classifies their domains
+// against the tracker list and checks the Subresource Integrity of external
+// scripts and stylesheets
+func AnalyzeThirdParties(doc *goquery.Document, page *url.URL) models.ThirdPartyReport {
+	report := models.ThirdPartyReport{
+		Summary:   models.ThirdPartySummary{ByCategory: make(map[string]int)},
+		Domains:   make([]models.ThirdPartyDomain, 0),
+		Resources: make([]models.ThirdPartyResource, 0),
+		SRIIssues: make([]models.SRIIssue, 0),
+	}
+
+	base := documentBase(doc, page)
+	pageSite := siteOf(page.Hostname())
+	seen := make(map[string]bool)
+	domains := make(map[string]*models.ThirdPartyDomain)
+
+	add := func(s *goquery.Selection, attr, resourceType string) {
+		ref, _ := s.Attr(attr)
+		target, err := base.Parse(strings.TrimSpace(ref))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			return
+		}
+		host := strings.ToLower(target.Hostname())
+		if host == "" || siteOf(host) == pageSite {
+			return
+		}
+
+		entry, listed := lookupTracker(host)
+		if resourceType == "" {
+			// Images only count as pixels when tiny or served by a tracker
+			if !isPixel(s) && !(listed && trackingCategories[entry.Category]) {
+				return
+			}
+			resourceType = models.ResourceTypePixel
+		}
+
+		key := resourceType + " " + target.String()
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		category := entry.Category
+		if !listed {
+			category = models.ThirdPartyUncategorized
+		}
+		resource := models.ThirdPartyResource{
+			URL:      target.String(),
+			Domain:   host,
+			Type:     resourceType,
+			Category: category,
+			Company:  entry.Company,
+		}
+
+		if resourceType == models.ResourceTypeScript || resourceType == models.ResourceTypeStylesheet {
+			integrity, ok := s.Attr("integrity")
+			switch {
+			case !ok || strings.TrimSpace(integrity) == "":
+				report.SRIIssues = append(report.SRIIssues, models.SRIIssue{URL: resource.URL, Type: resourceType, Issue: models.SRIMissing})
+				report.Summary.MissingIntegrity++
+			case !validIntegrity(integrity):
+				report.SRIIssues = append(report.SRIIssues, models.SRIIssue{URL: resource.URL, Type: resourceType, Issue: models.SRIInvalid})
+			default:
+				resource.Integrity = true
+			}
+		}
+		report.Resources = append(report.Resources, resource)
+
+		domain, ok := domains[host]
+		if !ok {
+			domain = &models.ThirdPartyDomain{
+				Domain:   host,
+				Category: category,
+				Company:  entry.Company,
+				Types:    make(map[string]int),
+			}
+			domains[host] = domain
+		}
+		domain.Resources++
+		domain.Types[resourceType]++
+	}
+
+	collect := func(doc *goquery.Document) {
+		doc.Find("script[src]").Each(func(i int, s *goquery.Selection) {
+			add(s, "src", models.ResourceTypeScript)
+		})
+		doc.Find("link[href]").Each(func(i int, s *goquery.Selection) {
+			rel, _ := s.Attr("rel")
+			for _, r := range strings.Fields(strings.ToLower(rel)) {
+				if r == "stylesheet" {
+					add(s, "href", models.ResourceTypeStylesheet)
+					return
+				}
+			}
+		})
+		doc.Find("iframe[src]").Each(func(i int, s *goquery.Selection) {
+			add(s, "src", models.ResourceTypeIframe)
+		})
+		doc.Find("img[src]").Each(func(i int, s *goquery.Selection) {
+			add(s, "src", "")
+		})
+	}
+	collect(doc)
+
+	// The parser keeps <noscript> content as text, which is where tracking
+	// pixels and iframe fallbacks usually live
+	doc.Find("noscript").Each(func(i int, s *goquery.Selection) {
+		if fallback, err := goquery.NewDocumentFromReader(strings.NewReader(s.Text())); err == nil {
+			collect(fallback)
+		}
+	})
+
+	for _, domain := range domains {
+		report.Domains = append(report.Domains, *domain)
+		report.Summary.ByCategory[domain.Category]++
+		if trackingCategories[domain.Category] {
+			report.Summary.Trackers++
+		}
+	}
+	sort.Slice(report.Domains, func(i, j int) bool {
+		return report.Domains[i].Domain < report.Domains[j].Domain
+	})
+	report.Summary.Domains = len(report.Domains)
+	report.Summary.Resources = len(report.Resources)
+	return report
+}
+
+// isPixel reports whether an image is declared at most 1x1 pixels
+func isPixel(s *goquery.Selection) bool {
+	width, _ := s.Attr("width")
+	height, _ := s.Attr("height")
+	w, errW := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(width), "px"))
+	h, errH := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(height), "px"))
+	return errW == nil && errH == nil && w <= 1 && h <= 1
+}
+
+// validIntegrity reports whether an integrity attribute holds at least one
+// well-formed hash: a supported algorithm, a dash and the base64 digest,
+// optionally followed by "?" and options
+func validIntegrity(integrity string) bool {
+	for _, token := range strings.Fields(integrity) {
+		algorithm, digest, ok := strings.Cut(token, "-")
+		if !ok {
+			continue
+		}
+		size, ok := sriDigestSizes[strings.ToLower(algorithm)]
+		if !ok {
+			continue
+		}
+		digest, _, _ = strings.Cut(digest, "?")
+		decoded, err := base64.StdEncoding.DecodeString(digest)
+		if err != nil {
+			decoded, err = base64.URLEncoding.DecodeString(digest)
+		}
+		if err == nil && len(decoded) == size {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"encoding/base64"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+func TestAnalyzeThirdParties(t *testing.T) {
+	sri := "sha384-" + base64.StdEncoding.EncodeToString(make([]byte, 48))
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head>
+		<script src="/app.js"></script>
+		<script src="https://static.example.co.uk/vendor.js"></script>
+		<script src="https://www.google-analytics.com/analytics.js"></script>
+		<script src="//www.google-analytics.com/analytics.js"></script>
+		<script src="https://cdn.lib.example/lib.js" integrity="` + sri + `"></script>
+		<link rel="Stylesheet" href="https://fonts.other.example/css" integrity="sha256-abc">
+		<link rel="preload" href="https://preload.example/font.woff2">
+	</head><body>
+		<iframe src="https://video.example/embed/1"></iframe>
+		<img src="https://images.other.example/logo.png" width="100" height="40">
+		<img src="https://px.tracker.example/p.gif" width="1px" height="1">
+		<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=">
+		<noscript><img src="https://www.facebook.com/tr?id=1"></noscript>
+	</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := url.Parse("https://www.example.co.uk/shop/")
+
+	report := AnalyzeThirdParties(doc, page)
+
+	wantResources := []models.ThirdPartyResource{
+		{URL: "https://www.google-analytics.com/analytics.js", Domain: "www.google-analytics.com", Type: models.ResourceTypeScript, Category: "Analytics", Company: "Google"},
+		{URL: "https://cdn.lib.example/lib.js", Domain: "cdn.lib.example", Type: models.ResourceTypeScript, Category: models.ThirdPartyUncategorized, Integrity: true},
+		{URL: "https://fonts.other.example/css", Domain: "fonts.other.example", Type: models.ResourceTypeStylesheet, Category: models.ThirdPartyUncategorized},
+		{URL: "https://video.example/embed/1", Domain: "video.example", Type: models.ResourceTypeIframe, Category: models.ThirdPartyUncategorized},
+		{URL: "https://px.tracker.example/p.gif", Domain: "px.tracker.example", Type: models.ResourceTypePixel, Category: models.ThirdPartyUncategorized},
+		{URL: "https://www.facebook.com/tr?id=1", Domain: "www.facebook.com", Type: models.ResourceTypePixel, Category: "Social", Company: "Meta"},
+	}
+	if !reflect.DeepEqual(report.Resources, wantResources) {
+		t.Errorf("resources = %+v, want %+v", report.Resources, wantResources)
+	}
+
+	wantIssues := []models.SRIIssue{
+		{URL: "https://www.google-analytics.com/analytics.js", Type: models.ResourceTypeScript, Issue: models.SRIMissing},
+		{URL: "https://fonts.other.example/css", Type: models.ResourceTypeStylesheet, Issue: models.SRIInvalid},
+	}
+	if !reflect.DeepEqual(report.SRIIssues, wantIssues) {
+		t.Errorf("SRI issues = %+v, want %+v", report.SRIIssues, wantIssues)
+	}
+
+	wantSummary := models.ThirdPartySummary{
+		Domains:          6,
+		Resources:        6,
+		Trackers:         2,
+		ByCategory:       map[string]int{models.ThirdPartyUncategorized: 4, "Analytics": 1, "Social": 1},
+		MissingIntegrity: 1,
+	}
+	if !reflect.DeepEqual(report.Summary, wantSummary) {
+		t.Errorf("summary = %+v, want %+v", report.Summary, wantSummary)
+	}
+
+	domains := make([]string, 0, len(report.Domains))
+	for _, d := range report.Domains {
+		domains = append(domains, d.Domain)
+	}
+	wantDomains := []string{"cdn.lib.example", "fonts.other.example", "px.tracker.example", "video.example", "www.facebook.com", "www.google-analytics.com"}
+	if !reflect.DeepEqual(domains, wantDomains) {
+		t.Errorf("domains = %v, want %v", domains, wantDomains)
+	}
+}
+
+func TestLookupTracker(t *testing.T) {
+	tests := []struct {
+		host       string
+		wantListed bool
+		category   string
+	}{
+		{host: "google-analytics.com", wantListed: true, category: "Analytics"},
+		{host: "ssl.google-analytics.com", wantListed: true, category: "Analytics"},
+		{host: "stats.g.doubleclick.net", wantListed: true, category: "Advertising"},
+		{host: "notgoogle-analytics.com"},
+		{host: "localhost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			entry, listed := lookupTracker(tt.host)
+			if listed != tt.wantListed || entry.Category != tt.category {
+				t.Errorf("lookupTracker(%q) = %+v, %v, want category %q, %v", tt.host, entry, listed, tt.category, tt.wantListed)
+			}
+		})
+	}
+}
+
+func TestSiteOf(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "www.example.com", want: "example.com"},
+		{host: "a.b.Example.co.uk.", want: "example.co.uk"},
+		{host: "user.github.io", want: "user.github.io"},
+		{host: "localhost", want: "localhost"},
+		{host: "192.0.2.1", want: "192.0.2.1"},
+		{host: "2001:db8::1", want: "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := siteOf(tt.host); got != tt.want {
+				t.Errorf("siteOf(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidIntegrity(t *testing.T) {
+	sha256 := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	sha512 := base64.URLEncoding.EncodeToString(append(make([]byte, 63), 0xff))
+	tests := []struct {
+		name      string
+		integrity string
+		want      bool
+	}{
+		{name: "sha256", integrity: "sha256-" + sha256, want: true},
+		{name: "algorithm case", integrity: "SHA256-" + sha256, want: true},
+		{name: "url-safe base64", integrity: "sha512-" + sha512, want: true},
+		{name: "options", integrity: "sha256-" + sha256 + "?ct=application/javascript", want: true},
+		{name: "one valid hash is enough", integrity: "md5-abc sha256-" + sha256, want: true},
+		{name: "wrong digest size", integrity: "sha384-" + sha256},
+		{name: "unsupported algorithm", integrity: "sha1-" + sha256},
+		{name: "not base64", integrity: "sha256-not*base64"},
+		{name: "no algorithm", integrity: sha256},
+		{name: "empty", integrity: " "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validIntegrity(tt.integrity); got != tt.want {
+				t.Errorf("validIntegrity(%q) = %v, want %v", tt.integrity, got, tt.want)
+			}
+		})
+	}
+}
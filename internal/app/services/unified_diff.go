@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDiffTooLarge is returned when two texts differ in more lines than the
+// diff is allowed to compute
+var ErrDiffTooLarge = errors.New("texts differ too much to compute a line diff")
+
+// maxDiffEdits bounds the work (and memory) of the Myers algorithm, which
+// grows with the square of the number of changed lines
+const maxDiffEdits = 4000
+
+type editOp int
+
+const (
+	opEqual editOp = iota
+	opDelete
+	opInsert
+)
+
+// lineEdit is one step of a line diff. aPos and bPos are the number of lines
+// of a and b that precede the edit.
+type lineEdit struct {
+	op   editOp
+	aPos int
+	bPos int
+	text string
+}
+
+// UnifiedDiff returns a unified diff of two texts with the given number of
+// context lines, or an empty string when the texts are identical
+func UnifiedDiff(fromName, toName, a, b string, context int) (string, error) {
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+
+	edits, err := myersDiff(aLines, bLines)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, hunk := range groupHunks(edits, context) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		writeHunk(&sb, hunk)
+	}
+	return sb.String(), nil
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// myersDiff computes a shortest edit script between a and b using Myers'
+// O(ND) algorithm
+func myersDiff(a, b []string) ([]lineEdit, error) {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+
+	// trace[d] holds v[-d..d] as it was before step d
+	var trace [][]int
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		if d > maxDiffEdits {
+			return nil, ErrDiffTooLarge
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the edit script
+	var reversed []lineEdit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := 0
+		if d > 0 {
+			prevX = at(prevK)
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, lineEdit{op: opEqual, aPos: x, bPos: y, text: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				reversed = append(reversed, lineEdit{op: opInsert, aPos: x, bPos: y, text: b[y]})
+			} else {
+				x--
+				reversed = append(reversed, lineEdit{op: opDelete, aPos: x, bPos: y, text: a[x]})
+			}
+		}
+	}
+
+	edits := make([]lineEdit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits, nil
+}
+
+// groupHunks splits an edit script into hunks of changes surrounded by up to
+// context unchanged lines. Changes separated by at most 2*context unchanged
+// lines share a hunk.
+func groupHunks(edits []lineEdit, context int) [][]lineEdit {
+	var hunks [][]lineEdit
+	start, end := -1, -1
+	for i, e := range edits {
+		if e.op == opEqual {
+			continue
+		}
+		if start >= 0 && i-end-1 > 2*context {
+			hunks = append(hunks, edits[start:minInt(end+context+1, len(edits))])
+			start = -1
+		}
+		if start < 0 {
+			start = maxInt(i-context, 0)
+		}
+		end = i
+	}
+	if start >= 0 {
+		hunks = append(hunks, edits[start:minInt(end+context+1, len(edits))])
+	}
+	return hunks
+}
+
+func writeHunk(sb *strings.Builder, hunk []lineEdit) {
+	aCount, bCount := 0, 0
+	for _, e := range hunk {
+		if e.op != opInsert {
+			aCount++
+		}
+		if e.op != opDelete {
+			bCount++
+		}
+	}
+
+	// Empty ranges are addressed by the line preceding them
+	aStart, bStart := hunk[0].aPos, hunk[0].bPos
+	if aCount > 0 {
+		aStart++
+	}
+	if bCount > 0 {
+		bStart++
+	}
+
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+	for _, e := range hunk {
+		switch e.op {
+		case opEqual:
+			sb.WriteByte(' ')
+		case opDelete:
+			sb.WriteByte('-')
+		case opInsert:
+			sb.WriteByte('+')
+		}
+		sb.WriteString(e.text)
+		sb.WriteByte('\n')
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMyersDiff(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		edits int
+	}{
+		{name: "identical", a: "a\nb\nc", b: "a\nb\nc", edits: 0},
+		{name: "both empty", a: "", b: "", edits: 0},
+		{name: "from empty", a: "", b: "a\nb", edits: 2},
+		{name: "to empty", a: "a\nb", b: "", edits: 2},
+		{name: "insert in middle", a: "a\nc", b: "a\nb\nc", edits: 1},
+		{name: "delete at start", a: "a\nb\nc", b: "b\nc", edits: 1},
+		{name: "replace line", a: "a\nb\nc", b: "a\nx\nc", edits: 2},
+		{name: "classic example", a: "a\nb\nc\na\nb\nb\na", b: "c\nb\na\nb\na\nc", edits: 5},
+		{name: "completely different", a: "a\nb\nc", b: "x\ny", edits: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := splitLines(tt.a), splitLines(tt.b)
+			edits, err := myersDiff(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Applying the script to a must give b, with positions that
+			// count the lines before each edit
+			var got []string
+			changes, aPos, bPos := 0, 0, 0
+			for _, e := range edits {
+				if e.aPos != aPos || e.bPos != bPos {
+					t.Fatalf("edit %+v at (%d,%d), want (%d,%d)", e, e.aPos, e.bPos, aPos, bPos)
+				}
+				switch e.op {
+				case opEqual:
+					if a[aPos] != e.text || b[bPos] != e.text {
+						t.Fatalf("equal edit %q does not match", e.text)
+					}
+					got = append(got, e.text)
+					aPos++
+					bPos++
+				case opDelete:
+					if a[aPos] != e.text {
+						t.Fatalf("delete edit %q does not match %q", e.text, a[aPos])
+					}
+					changes++
+					aPos++
+				case opInsert:
+					got = append(got, e.text)
+					changes++
+					bPos++
+				}
+			}
+			if aPos != len(a) || strings.Join(got, "\n") != strings.Join(b, "\n") {
+				t.Errorf("edit script turns %q into %q, want %q", tt.a, strings.Join(got, "\n"), tt.b)
+			}
+			if changes != tt.edits {
+				t.Errorf("edit script has %d changes, want %d", changes, tt.edits)
+			}
+		})
+	}
+}
+
+func TestMyersDiffTooLarge(t *testing.T) {
+	a := make([]string, maxDiffEdits+1)
+	b := make([]string, maxDiffEdits+1)
+	for i := range a {
+		a[i] = "a"
+		b[i] = "b"
+	}
+	if _, err := myersDiff(a, b); err != ErrDiffTooLarge {
+		t.Errorf("myersDiff returned %v, want ErrDiffTooLarge", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{name: "identical", a: "a\nb\n", b: "a\nb\n", context: 3, want: ""},
+		{
+			name: "one change", a: "a\nb\nc\n", b: "a\nx\nc\n", context: 1,
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "separate hunks", a: "1\n2\n3\n4\n5\n6\n7\n8\n", b: "x\n2\n3\n4\n5\n6\n7\ny\n", context: 1,
+			want: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+y\n",
+		},
+		{
+			name: "close changes share a hunk", a: "1\n2\n3\n4\n", b: "x\n2\n3\ny\n", context: 1,
+			want: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n-4\n+y\n",
+		},
+		{
+			name: "insert into empty", a: "", b: "a\n", context: 3,
+			want: "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			name: "delete everything", a: "a\nb\n", b: "", context: 3,
+			want: "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnifiedDiff("old", "new", tt.a, tt.b, tt.context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
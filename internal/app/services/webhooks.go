@@ -0,0 +1,317 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+	"github.com/ayeshakhan-29/test-task-BE/internal/logger"
+	"gorm.io/gorm"
+)
+
+// Headers sent with every webhook delivery. The signature is the hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook secret.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// webhookTimeout bounds a single delivery attempt
+const webhookTimeout = 10 * time.Second
+
+// deliveryBatchSize limits how many due deliveries are claimed per poll
+const deliveryBatchSize = 50
+
+// WebhookEvents lists the events webhooks can subscribe to
+var WebhookEvents = map[string]bool{
+	models.WebhookEventCrawlCompleted: true,
+	models.WebhookEventCrawlFailed:    true,
+	models.WebhookEventLinkBroken:     true,
+	models.WebhookEventAlertTriggered: true,
+}
+
+// SignWebhookPayload returns the signature header value of a delivery body
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateWebhookSecret returns a random secret for signing deliveries
+func GenerateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// WebhookDispatcher stores events as deliveries to the subscribed webhooks
+// and posts them in the background. Failed deliveries are retried after
+// baseBackoff, 2*baseBackoff, 4*baseBackoff, ... and are moved to the dead
+// state once maxAttempts attempts have failed.
+type WebhookDispatcher struct {
+	db           *gorm.DB
+	client       *http.Client
+	maxAttempts  int
+	baseBackoff  time.Duration
+	pollInterval time.Duration
+	wake         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewWebhookDispatcher creates a dispatcher. A nil client uses a default
+// client with a short timeout.
+func NewWebhookDispatcher(db *gorm.DB, client *http.Client, maxAttempts int, baseBackoff, pollInterval time.Duration) *WebhookDispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &WebhookDispatcher{
+		db:           db,
+		client:       client,
+		maxAttempts:  maxAttempts,
+		baseBackoff:  baseBackoff,
+		pollInterval: pollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Start begins sending due deliveries until ctx is cancelled
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			d.DeliverDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the delivery loop has exited
+func (d *WebhookDispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Publish stores a delivery of the event for every active webhook of the
+// user that subscribes to it. Publishing never fails the caller; errors are
+// logged.
+func (d *WebhookDispatcher) Publish(userID uint64, event string, data interface{}) {
+	if d == nil {
+		return
+	}
+
+	var webhooks []models.Webhook
+	if err := d.db.Where("user_id = ? AND active = ?", userID, true).Find(&webhooks).Error; err != nil {
+		logger.Error("Failed to load webhooks for %s: %v", event, err)
+		return
+	}
+
+	var deliveries []models.WebhookDelivery
+	var payload []byte
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !subscribes(webhook, event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(models.WebhookEvent{Event: event, CreatedAt: now, Data: data})
+			if err != nil {
+				logger.Error("Failed to encode %s event: %v", event, err)
+				return
+			}
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			UserID:        userID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.DeliveryStatusPending,
+			NextAttemptAt: &now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err := d.db.Create(&deliveries).Error; err != nil {
+		logger.Error("Failed to store %s deliveries: %v", event, err)
+		return
+	}
+	d.notify()
+}
+
+// Redeliver queues a delivery to be sent again immediately with a fresh set
+// of attempts, whatever its current status
+func (d *WebhookDispatcher) Redeliver(delivery *models.WebhookDelivery) error {
+	now := time.Now()
+	delivery.Status = models.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	delivery.LastError = ""
+
+	if err := d.db.Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_error").
+		Updates(delivery).Error; err != nil {
+		return err
+	}
+	d.notify()
+	return nil
+}
+
+// DeliverDue sends every pending delivery whose next attempt is due
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) {
+	var due []models.WebhookDelivery
+	if err := d.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, time.Now()).
+		Order("next_attempt_at").
+		Limit(deliveryBatchSize).
+		Find(&due).Error; err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Failed to load due webhook deliveries: %v", err)
+		}
+		return
+	}
+
+	for i := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		d.attempt(ctx, &due[i])
+	}
+}
+
+// notify wakes up the delivery loop without blocking
+func (d *WebhookDispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// attempt claims a due delivery and posts it to its webhook
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	// Claim the attempt and push the next attempt back, so another instance
+	// or a crash mid-request cannot send the same attempt twice
+	now := time.Now()
+	lease := now.Add(webhookTimeout + d.backoff(delivery.Attempts+1))
+	claim := d.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, models.DeliveryStatusPending, delivery.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        delivery.Attempts + 1,
+			"last_attempt_at": now,
+			"next_attempt_at": lease,
+		})
+	if claim.Error != nil || claim.RowsAffected != 1 {
+		return
+	}
+	delivery.Attempts++
+
+	var webhook models.Webhook
+	if err := d.db.WithContext(ctx).First(&webhook, delivery.WebhookID).Error; err != nil {
+		d.recordAttempt(delivery, 0, fmt.Errorf("webhook not found: %w", err))
+		return
+	}
+
+	status, err := d.send(ctx, &webhook, delivery)
+	d.recordAttempt(delivery, status, err)
+}
+
+// send posts the delivery and returns the response status code
+func (d *WebhookDispatcher) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// recordAttempt stores the outcome of an attempt and schedules the retry
+func (d *WebhookDispatcher) recordAttempt(delivery *models.WebhookDelivery, status int, err error) {
+	updates := map[string]interface{}{
+		"response_status": status,
+		"last_error":      "",
+	}
+	switch {
+	case err == nil:
+		updates["status"] = models.DeliveryStatusSucceeded
+		updates["next_attempt_at"] = nil
+	case delivery.Attempts >= d.maxAttempts:
+		updates["status"] = models.DeliveryStatusDead
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = err.Error()
+	default:
+		updates["next_attempt_at"] = time.Now().Add(d.backoff(delivery.Attempts))
+		updates["last_error"] = err.Error()
+	}
+
+	if err := d.db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		logger.Error("Failed to record webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// backoff returns the delay before the retry that follows the given attempt
+func (d *WebhookDispatcher) backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 20 {
+		attempt = 20
+	}
+	return d.baseBackoff * time.Duration(1<<uint(attempt-1))
+}
+
+func subscribes(webhook models.Webhook, event string) bool {
+	for _, e := range webhook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
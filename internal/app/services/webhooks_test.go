@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ayeshakhan-29/test-task-BE/internal/app/models"
+)
+
+// webhookReceiver is a local endpoint that answers deliveries with the
+// configured status and records what it received
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []receivedDelivery
+}
+
+type receivedDelivery struct {
+	header http.Header
+	body   []byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, receivedDelivery{header: req.Header.Clone(), body: body})
+	w.WriteHeader(r.status)
+}
+
+func (r *webhookReceiver) respondWith(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *webhookReceiver) received() []receivedDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedDelivery(nil), r.requests...)
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &models.User{}, &models.Webhook{}, &models.WebhookDelivery{})
+
+	receiver := &webhookReceiver{status: http.StatusInternalServerError}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	const secret = "test-secret"
+	const backoff = time.Hour
+	webhook := models.Webhook{
+		UserID: 1,
+		URL:    server.URL,
+		Secret: secret,
+		Events: models.StringSlice{models.WebhookEventCrawlCompleted},
+		Active: true,
+	}
+	if err := db.Create(&webhook).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher := NewWebhookDispatcher(db, server.Client(), 3, backoff, time.Minute)
+	dispatcher.Publish(1, models.WebhookEventCrawlCompleted, models.CrawlEventData{CrawlID: 7, URL: "https://example.com/"})
+	dispatcher.Publish(1, models.WebhookEventCrawlFailed, models.CrawlEventData{CrawlID: 8})
+
+	var delivery models.WebhookDelivery
+	load := func() {
+		t.Helper()
+		delivery = models.WebhookDelivery{}
+		if err := db.First(&delivery).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	makeDue := func() {
+		t.Helper()
+		if err := db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).
+			Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var count int64
+	db.Model(&models.WebhookDelivery{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("Publish stored %d deliveries, want 1 for the subscribed event", count)
+	}
+
+	// The first attempt fails and is retried after the base backoff
+	before := time.Now()
+	dispatcher.DeliverDue(ctx)
+	load()
+	requests := receiver.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	if delivery.Status != models.DeliveryStatusPending || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusInternalServerError {
+		t.Errorf("after a failed attempt: status %s, attempts %d, response %d", delivery.Status, delivery.Attempts, delivery.ResponseStatus)
+	}
+	assertRetryAfter(t, delivery, before, backoff)
+
+	// The request carries a signature over the timestamp and the body
+	req := requests[0]
+	timestamp, err := strconv.ParseInt(req.header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp header %q", req.header.Get(WebhookTimestampHeader))
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age < -time.Second || age > time.Minute {
+		t.Errorf("timestamp header is %v old", age)
+	}
+	if got, want := req.header.Get(WebhookSignatureHeader), SignWebhookPayload(secret, timestamp, req.body); got != want {
+		t.Errorf("signature header = %q, want %q", got, want)
+	}
+	if got := req.header.Get(WebhookEventHeader); got != models.WebhookEventCrawlCompleted {
+		t.Errorf("event header = %q", got)
+	}
+	if got := req.header.Get(WebhookDeliveryHeader); got != strconv.FormatUint(uint64(delivery.ID), 10) {
+		t.Errorf("delivery header = %q, want %d", got, delivery.ID)
+	}
+	if string(req.body) != delivery.Payload {
+		t.Errorf("body = %s, want the stored payload %s", req.body, delivery.Payload)
+	}
+
+	// Nothing is sent before the retry is due
+	dispatcher.DeliverDue(ctx)
+	if n := len(receiver.received()); n != 1 {
+		t.Fatalf("receiver got %d requests before the retry was due, want 1", n)
+	}
+
+	// The second failure doubles the backoff
+	makeDue()
+	before = time.Now()
+	dispatcher.DeliverDue(ctx)
+	load()
+	if delivery.Status != models.DeliveryStatusPending || delivery.Attempts != 2 {
+		t.Errorf("after the second attempt: status %s, attempts %d", delivery.Status, delivery.Attempts)
+	}
+	assertRetryAfter(t, delivery, before, 2*backoff)
+
+	// The last attempt moves the delivery to dead
+	makeDue()
+	dispatcher.DeliverDue(ctx)
+	load()
+	if delivery.Status != models.DeliveryStatusDead || delivery.Attempts != 3 || delivery.NextAttemptAt != nil || delivery.LastError == "" {
+		t.Errorf("after the last attempt: status %s, attempts %d, next attempt %v, error %q",
+			delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError)
+	}
+	dispatcher.DeliverDue(ctx)
+	if n := len(receiver.received()); n != 3 {
+		t.Fatalf("receiver got %d requests, want 3", n)
+	}
+
+	// Redelivery starts over with a fresh set of attempts
+	receiver.respondWith(http.StatusNoContent)
+	if err := dispatcher.Redeliver(&delivery); err != nil {
+		t.Fatal(err)
+	}
+	dispatcher.DeliverDue(ctx)
+	load()
+	if delivery.Status != models.DeliveryStatusSucceeded || delivery.Attempts != 1 ||
+		delivery.ResponseStatus != http.StatusNoContent || delivery.LastError != "" || delivery.NextAttemptAt != nil {
+		t.Errorf("after redelivery: status %s, attempts %d, response %d, error %q, next attempt %v",
+			delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.LastError, delivery.NextAttemptAt)
+	}
+	if n := len(receiver.received()); n != 4 {
+		t.Fatalf("receiver got %d requests, want 4", n)
+	}
+}
+
+func assertRetryAfter(t *testing.T, delivery models.WebhookDelivery, attemptedAt time.Time, backoff time.Duration) {
+	t.Helper()
+	if delivery.NextAttemptAt == nil {
+		t.Fatal("no retry scheduled")
+	}
+	earliest := attemptedAt.Add(backoff)
+	latest := time.Now().Add(backoff)
+	if delivery.NextAttemptAt.Before(earliest.Add(-time.Second)) || delivery.NextAttemptAt.After(latest.Add(time.Second)) {
+		t.Errorf("retry scheduled at %v, want %v after the attempt", delivery.NextAttemptAt, backoff)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(nil, nil, 5, time.Minute, time.Minute)
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Minute},
+		{attempt: 1, want: time.Minute},
+		{attempt: 2, want: 2 * time.Minute},
+		{attempt: 3, want: 4 * time.Minute},
+		{attempt: 20, want: (1 << 19) * time.Minute},
+		{attempt: 50, want: (1 << 19) * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := dispatcher.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -3,9 +3,17 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// defaultTrackingParams are the query parameters stripped from URLs unless
+// URL_TRACKING_PARAMS lists others
+var defaultTrackingParams = []string{
+	"utm_*", "gclid", "dclid", "gbraid", "wbraid", "fbclid", "msclkid", "yclid",
+	"mc_cid", "mc_eid", "_ga", "_gl", "igshid",
+}
+
 // Config holds all configuration for the application
 type Config struct {
 	Environment string
@@ -13,6 +21,15 @@ type Config struct {
 	ServerPort string
 	Database   DatabaseConfig
 	Server     ServerConfig
+	Analysis   AnalysisConfig
+	Storage    StorageConfig
+	History    HistoryConfig
+	Scheduler  SchedulerConfig
+	Webhooks   WebhookConfig
+	Batch      BatchConfig
+	LinkCache  LinkCacheConfig
+	Politeness PolitenessConfig
+	URLs       URLConfig
 }
 
 // DatabaseConfig holds database configuration
@@ -36,6 +53,90 @@ type ServerConfig struct {
 	ReadHeaderTimeout time.Duration
 }
 
+// AnalysisConfig holds settings for the page analyzers
+type AnalysisConfig struct {
+	// SimilarityThreshold is the default minimum SimHash similarity (0-1)
+	// for two pages to be reported as near-duplicates
+	SimilarityThreshold float64
+	// TechRulesPath is a technology fingerprint rules file used instead of
+	// the bundled rules; empty uses the bundled rules
+	TechRulesPath string
+}
+
+// StorageConfig holds settings for HTML snapshot storage
+type StorageConfig struct {
+	// Path is the root directory of the local snapshot store
+	Path string
+	// SnapshotQuota is the per-user snapshot quota in bytes; 0 disables it
+	SnapshotQuota int64
+	// GCInterval is how often snapshots no crawl references are deleted
+	GCInterval time.Duration
+}
+
+// HistoryConfig holds settings for the crawl run history
+type HistoryConfig struct {
+	// RunLimit is the number of unpinned runs kept per URL; 0 keeps every run
+	RunLimit int
+}
+
+// SchedulerConfig holds settings for scheduled recrawls
+type SchedulerConfig struct {
+	Enabled      bool
+	PollInterval time.Duration
+	// Jitter is the maximum random delay added before a scheduled crawl
+	Jitter    time.Duration
+	Workers   int
+	QueueSize int
+}
+
+// WebhookConfig holds settings for outbound webhook deliveries
+type WebhookConfig struct {
+	// MaxAttempts is the number of attempts before a delivery is dead-lettered
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry; it doubles every retry
+	BaseBackoff  time.Duration
+	PollInterval time.Duration
+}
+
+// BatchConfig holds settings for bulk crawl submissions
+type BatchConfig struct {
+	// Concurrency is the number of crawls of a batch that run at a time
+	Concurrency int
+	// MaxURLs is the largest accepted batch; 0 disables the limit
+	MaxURLs int
+}
+
+// LinkCacheConfig holds settings for the shared link status cache
+type LinkCacheConfig struct {
+	// SuccessTTL and FailureTTL are how long accessible and broken links are
+	// cached; 0 disables caching of that outcome
+	SuccessTTL time.Duration
+	FailureTTL time.Duration
+	// Persistent adds a database tier shared by every instance
+	Persistent bool
+}
+
+// PolitenessConfig holds the limits on requests to crawled sites, shared by
+// every crawl and user
+type PolitenessConfig struct {
+	// Delay is the minimum time between the starts of two requests to a host
+	Delay time.Duration
+	// HostConcurrency is the number of requests to a host at a time
+	HostConcurrency int
+	// RespectRobots raises the delay to the robots.txt Crawl-delay, capped
+	// at MaxCrawlDelay
+	RespectRobots bool
+	MaxCrawlDelay time.Duration
+}
+
+// URLConfig holds settings for URL canonicalization
+type URLConfig struct {
+	StripTrailingSlash bool
+	// TrackingParams are the query parameters stripped from URLs; a
+	// trailing "*" matches a prefix. nil keeps every parameter.
+	TrackingParams []string
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
 	// Set default values
@@ -60,6 +161,54 @@ func LoadConfig() (*Config, error) {
 			IdleTimeout:       time.Duration(getEnvAsInt("SERVER_IDLE_TIMEOUT", 60)) * time.Second,
 			ReadHeaderTimeout: time.Duration(getEnvAsInt("SERVER_READ_HEADER_TIMEOUT", 5)) * time.Second,
 		},
+		Analysis: AnalysisConfig{
+			SimilarityThreshold: getEnvAsFloat("SIMILARITY_THRESHOLD", 0.9),
+			TechRulesPath:       getEnv("TECH_RULES_PATH", ""),
+		},
+		Storage: StorageConfig{
+			Path:          getEnv("STORAGE_PATH", "data/snapshots"),
+			SnapshotQuota: int64(getEnvAsInt("SNAPSHOT_QUOTA_MB", 100)) * 1024 * 1024,
+			GCInterval:    time.Duration(getEnvAsInt("SNAPSHOT_GC_INTERVAL", 60)) * time.Minute,
+		},
+		History: HistoryConfig{
+			RunLimit: getEnvAsInt("CRAWL_HISTORY_LIMIT", 50),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:      getEnvAsBool("SCHEDULER_ENABLED", true),
+			PollInterval: time.Duration(getEnvAsInt("SCHEDULER_POLL_INTERVAL", 30)) * time.Second,
+			Jitter:       time.Duration(getEnvAsInt("SCHEDULER_JITTER", 30)) * time.Second,
+			Workers:      getEnvAsInt("CRAWL_WORKERS", 2),
+			QueueSize:    getEnvAsInt("CRAWL_QUEUE_SIZE", 100),
+		},
+		Webhooks: WebhookConfig{
+			MaxAttempts:  getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 6),
+			BaseBackoff:  time.Duration(getEnvAsInt("WEBHOOK_BASE_BACKOFF", 30)) * time.Second,
+			PollInterval: time.Duration(getEnvAsInt("WEBHOOK_POLL_INTERVAL", 10)) * time.Second,
+		},
+		Batch: BatchConfig{
+			Concurrency: getEnvAsInt("BATCH_CONCURRENCY", 4),
+			MaxURLs:     getEnvAsInt("BATCH_MAX_URLS", 1000),
+		},
+		LinkCache: LinkCacheConfig{
+			SuccessTTL: time.Duration(getEnvAsInt("LINK_CACHE_SUCCESS_TTL", 3600)) * time.Second,
+			FailureTTL: time.Duration(getEnvAsInt("LINK_CACHE_FAILURE_TTL", 300)) * time.Second,
+			Persistent: getEnvAsBool("LINK_CACHE_PERSISTENT", false),
+		},
+		Politeness: PolitenessConfig{
+			Delay:           time.Duration(getEnvAsInt("HOST_REQUEST_DELAY_MS", 200)) * time.Millisecond,
+			HostConcurrency: getEnvAsInt("HOST_CONCURRENCY", 1),
+			RespectRobots:   getEnvAsBool("RESPECT_ROBOTS_CRAWL_DELAY", true),
+			MaxCrawlDelay:   time.Duration(getEnvAsInt("MAX_CRAWL_DELAY", 10)) * time.Second,
+		},
+		URLs: URLConfig{
+			StripTrailingSlash: getEnvAsBool("URL_STRIP_TRAILING_SLASH", false),
+		},
+	}
+	if getEnvAsBool("URL_STRIP_TRACKING_PARAMS", true) {
+		cfg.URLs.TrackingParams = defaultTrackingParams
+		if params := getEnv("URL_TRACKING_PARAMS", ""); params != "" {
+			cfg.URLs.TrackingParams = strings.Split(params, ",")
+		}
 	}
 
 	return cfg, nil
@@ -83,6 +232,24 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsBool gets an environment variable as a boolean or returns a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsFloat gets an environment variable as a float or returns a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 // IsProduction returns true if the environment is set to production
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
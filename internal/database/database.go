@@ -49,7 +49,19 @@ func NewDatabase() (*Database, error) {
 	fmt.Println("Running database migrations...")
 	err = db.AutoMigrate(
 		&models.User{},
+		&models.TrackedURL{},
 		&models.CrawlResult{},
+		&models.Snapshot{},
+		&models.Schedule{},
+		&models.AlertRule{},
+		&models.Alert{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Batch{},
+		&models.BatchItem{},
+		&models.LinkEdge{},
+		&models.CachedLinkStatus{},
+		&models.ScoringProfile{},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
@@ -12,12 +12,46 @@ func (db *Database) RunMigrations() error {
 	// based on the model definitions
 	err := db.DB.AutoMigrate(
 		&models.User{},
+		&models.TrackedURL{},
 		&models.CrawlResult{},
+		&models.Snapshot{},
+		&models.Schedule{},
+		&models.AlertRule{},
+		&models.Alert{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Batch{},
+		&models.BatchItem{},
+		&models.LinkEdge{},
+		&models.CachedLinkStatus{},
+		&models.ScoringProfile{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	if err := db.backfillTrackedURLs(); err != nil {
+		return fmt.Errorf("failed to backfill tracked URLs: %w", err)
+	}
+
 	return nil
 }
+
+// backfillTrackedURLs creates a tracked URL for every crawl stored before run
+// history existed. Those crawls were unique per user and URL, so each one
+// becomes the single run of its own tracked URL. The statements use MySQL
+// syntax (NOW() and UPDATE ... JOIN), like the rest of the schema.
+func (db *Database) backfillTrackedURLs() error {
+	return db.DB.Exec(`
+		INSERT INTO tracked_urls (user_id, url, latest_run_id, run_count, last_crawled_at, created_at, updated_at)
+		SELECT user_id, url, id, 1, updated_at, created_at, NOW()
+		FROM crawl_results
+		WHERE (tracked_url_id IS NULL OR tracked_url_id = 0) AND deleted_at IS NULL
+	`).Exec(`
+		UPDATE crawl_results cr
+		JOIN tracked_urls t ON t.latest_run_id = cr.id
+		SET cr.tracked_url_id = t.id
+		WHERE cr.tracked_url_id IS NULL OR cr.tracked_url_id = 0
+	`).Error
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when a blob does not exist in the store
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore persists immutable blobs addressed by a key. Implementations must
+// be safe for concurrent use; writing an existing key replaces its content.
+type BlobStore interface {
+	// Put stores data under key
+	Put(ctx context.Context, key string, data []byte) error
+	// Get opens the blob stored under key. The caller must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Exists reports whether a blob is stored under key
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete removes the blob stored under key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}